@@ -7,6 +7,10 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
+
+	"gator/internal/metrics"
 )
 
 type RSSFeed struct {
@@ -23,6 +27,8 @@ type RSSItem struct {
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
 }
 
 func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -33,24 +39,16 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	req.Header.Set("User-Agent", "gator")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch feed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	start := time.Now()
+	body, err := download(req)
+	metrics.FetchDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
 	var feed RSSFeed
 	if err := xml.Unmarshal(body, &feed); err != nil {
+		metrics.ParseErrors.Inc()
 		return nil, fmt.Errorf("failed to unmarshal XML: %w", err)
 	}
 
@@ -64,3 +62,26 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	return &feed, nil
 }
+
+// download sends req and returns the body of a 200 response.
+func download(req *http.Request) ([]byte, error) {
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		metrics.FeedFetches.WithLabelValues(metrics.FetchError).Inc()
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	metrics.FeedFetches.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	metrics.FetchedBytes.Add(float64(len(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, nil
+}
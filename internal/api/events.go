@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"gator/internal/cursor"
+	"gator/internal/database"
+	"gator/internal/events"
+)
+
+// keepaliveInterval is how often an idle stream sends a comment so that
+// proxies don't close the connection. Tests shorten it.
+var keepaliveInterval = 30 * time.Second
+
+// replayBatch is how many missed posts are read at a time when a client
+// resumes with Last-Event-ID.
+const replayBatch = 100
+
+// NewEventServer serves only the Server-Sent Events stream of posts
+// published on bus. It runs alongside the aggregator, in the process that
+// publishes the events.
+func NewEventServer(db database.Store, bus *events.Bus, logger *slog.Logger) *Server {
+	s := &Server{
+		db:     db,
+		logger: logger,
+		mux:    http.NewServeMux(),
+		bus:    bus,
+	}
+	s.handleKeyParam("GET /events", s.streamPosts)
+	return s
+}
+
+// streamPosts sends each new post in a feed the user follows as a "post"
+// event whose data is the post as JSON, in the same shape as the posts
+// API. Event IDs are cursors over (fetch time, post ID), so a client that
+// reconnects with Last-Event-ID is first sent every post it missed,
+// including ones stored while this server was down.
+func (s *Server) streamPosts(w http.ResponseWriter, r *http.Request) error {
+	u := requestUser(r)
+	var last cursor.Cursor
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		var err error
+		if last, err = cursor.Decode(id); err != nil {
+			return errorf(http.StatusBadRequest, "invalid Last-Event-ID")
+		}
+	}
+
+	// Subscribe before replaying so nothing published in between is lost.
+	// Anything received twice is skipped by comparing it with last.
+	sub := s.bus.Subscribe()
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, ": gator new posts\n\n"); err != nil {
+		return nil
+	}
+
+	ctx := r.Context()
+	send := func(p post) error {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		last = cursor.Cursor{Time: p.FetchedAt, ID: p.ID}
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: post\ndata: %s\n\n", last.Encode(), data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	for !last.Time.IsZero() {
+		rows, err := s.db.GetPostsForUserAfter(ctx, database.GetPostsForUserAfterParams{
+			UserID:    u.ID,
+			AfterTime: last.Time,
+			AfterID:   last.ID,
+			Limit:     replayBatch,
+		})
+		if err != nil {
+			s.logger.Error("failed to replay posts", "user", u.Name, "error", err)
+			return nil
+		}
+		for _, p := range rows {
+			err := send(post{
+				ID:          p.ID,
+				Title:       p.Title,
+				URL:         p.Url,
+				Description: p.Description.String,
+				Author:      p.Author.String,
+				FeedID:      p.FeedID,
+				Feed:        p.FeedName,
+				PublishedAt: nullTime(p.PublishedAt),
+				FetchedAt:   p.CreatedAt,
+			})
+			if err != nil {
+				return nil
+			}
+		}
+		if len(rows) < replayBatch {
+			break
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return nil
+	}
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+			if err := rc.Flush(); err != nil {
+				return nil
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind. The client reconnects with
+				// Last-Event-ID and catches up from the database.
+				return nil
+			}
+			p := e.Post
+			if !last.Time.IsZero() && !after(p, last) {
+				continue
+			}
+			_, err := s.db.GetFeedFollowForUserByURL(ctx, database.GetFeedFollowForUserByURLParams{UserID: u.ID, Url: e.Feed.Url})
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				s.logger.Error("failed to check feed follow", "user", u.Name, "feed_id", e.Feed.ID, "error", err)
+				return nil
+			}
+			err = send(post{
+				ID:          p.ID,
+				Title:       p.Title,
+				URL:         p.Url,
+				Description: p.Description.String,
+				Author:      p.Author.String,
+				FeedID:      p.FeedID,
+				Feed:        e.Feed.Name,
+				PublishedAt: nullTime(p.PublishedAt),
+				FetchedAt:   p.CreatedAt,
+			})
+			if err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// after reports whether p comes after c in (fetch time, ID) order.
+func after(p database.Post, c cursor.Cursor) bool {
+	if !p.CreatedAt.Equal(c.Time) {
+		return p.CreatedAt.After(c.Time)
+	}
+	return bytes.Compare(p.ID[:], c.ID[:]) > 0
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gator/internal/auth"
+	"gator/internal/cursor"
+	"gator/internal/database"
+	"gator/internal/database/memory"
+	"gator/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// sseEvent is one event read from a stream; comment is set for comments.
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+// stream is an open connection to the event stream.
+type stream struct {
+	r     *bufio.Reader
+	close func()
+}
+
+func openStream(t *testing.T, srv *httptest.Server, token, lastEventID string) *stream {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"/events?key="+url.QueryEscape(token), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("status = %d, body %s", resp.StatusCode, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+	s := &stream{r: bufio.NewReader(resp.Body), close: func() { cancel(); resp.Body.Close() }}
+	t.Cleanup(s.close)
+	return s
+}
+
+// next reads the next event or comment.
+func (s *stream) next(t *testing.T) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return e
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			e.comment = value
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+// posts reads post events up to the next keepalive and returns their
+// titles and the last event ID.
+func (s *stream) posts(t *testing.T) ([]string, string) {
+	t.Helper()
+	var titles []string
+	var last string
+	for {
+		e := s.next(t)
+		if e.comment == "keepalive" {
+			return titles, last
+		}
+		if e.event != "post" {
+			t.Fatalf("got %+v, want a post event", e)
+		}
+		var p post
+		if err := json.Unmarshal([]byte(e.data), &p); err != nil {
+			t.Fatalf("event data %s: %v", e.data, err)
+		}
+		titles = append(titles, p.Title)
+		last = e.id
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	old := keepaliveInterval
+	keepaliveInterval = 50 * time.Millisecond
+	t.Cleanup(func() { keepaliveInterval = old })
+
+	ts := newTestServer(t, memory.New())
+	ctx := context.Background()
+	bus := events.NewBus()
+	srv := httptest.NewServer(NewEventServer(ts.db, bus, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	// Cleanups run last first, so open streams are closed before this
+	// waits for their handlers to return.
+	t.Cleanup(srv.Close)
+	token := createKey(t, ts.db, ts.alice, auth.ScopeFeeds)
+
+	// Nobody follows feed C, so its posts are never sent.
+	feedC, err := ts.db.CreateFeed(ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Name: "C", Url: "https://c.example.com/rss", UserID: ts.bob.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	fetched := time.Now().UTC().Truncate(time.Microsecond)
+	publish := func(title string, feed database.Feed) {
+		t.Helper()
+		fetched = fetched.Add(time.Second)
+		p := database.Post{
+			ID:        uuid.New(),
+			CreatedAt: fetched,
+			UpdatedAt: fetched,
+			Title:     title,
+			Url:       "https://example.com/" + title,
+			FeedID:    feed.ID,
+		}
+		if _, err := ts.db.CreatePost(ctx, database.CreatePostParams(p)); err != nil {
+			t.Fatalf("CreatePost: %v", err)
+		}
+		bus.Publish(events.PostCreated{Post: p, Feed: feed})
+	}
+
+	// A new client is only sent posts published while it is connected.
+	s := openStream(t, srv, token, "")
+	if e := s.next(t); e.comment != "gator new posts" {
+		t.Fatalf("first event = %+v, want the opening comment", e)
+	}
+	publish("q1", feedC)
+	publish("p6", ts.feedA)
+	titles, lastID := s.posts(t)
+	if !slices.Equal(titles, []string{"p6"}) {
+		t.Fatalf("live posts = %v, want [p6]", titles)
+	}
+	s.close()
+
+	// Posts stored while it was away are replayed in order on reconnecting,
+	// then it is sent new posts again.
+	publish("p7", ts.feedB)
+	publish("q2", feedC)
+	publish("p8", ts.feedA)
+	s = openStream(t, srv, token, lastID)
+	s.next(t)
+	titles, _ = s.posts(t)
+	if !slices.Equal(titles, []string{"p7", "p8"}) {
+		t.Errorf("replayed posts = %v, want [p7 p8]", titles)
+	}
+	publish("p9", ts.feedB)
+	if titles, _ = s.posts(t); !slices.Equal(titles, []string{"p9"}) {
+		t.Errorf("live posts after replay = %v, want [p9]", titles)
+	}
+
+	// Keepalives continue while nothing is published.
+	for i := range 2 {
+		if e := s.next(t); e.comment != "keepalive" {
+			t.Errorf("idle event %d = %+v, want a keepalive", i, e)
+		}
+	}
+
+	// Resuming from an older post replays everything after it.
+	p2, err := ts.db.GetPostByURL(ctx, "https://example.com/2")
+	if err != nil {
+		t.Fatalf("GetPostByURL: %v", err)
+	}
+	s = openStream(t, srv, token, cursor.Cursor{Time: p2.CreatedAt, ID: p2.ID}.Encode())
+	s.next(t)
+	titles, _ = s.posts(t)
+	if want := []string{"p3", "p4", "p5", "p6", "p7", "p8", "p9"}; !slices.Equal(titles, want) {
+		t.Errorf("replayed posts = %v, want %v", titles, want)
+	}
+
+}
+
+func TestEventStreamInvalidLastEventID(t *testing.T) {
+	ts := newTestServer(t, memory.New())
+	srv := httptest.NewServer(NewEventServer(ts.db, events.NewBus(), slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer srv.Close()
+	token := createKey(t, ts.db, ts.alice, auth.ScopeFeeds)
+
+	req, err := http.NewRequest("GET", srv.URL+"/events?key="+url.QueryEscape(token), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "nope")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkError(t, resp, body, http.StatusBadRequest, "invalid Last-Event-ID")
+}
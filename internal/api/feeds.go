@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type feed struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	UserID        uuid.UUID  `json:"user_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+func newFeed(f database.Feed) feed {
+	return feed{
+		ID:            f.ID,
+		Name:          f.Name,
+		URL:           f.Url,
+		UserID:        f.UserID,
+		CreatedAt:     f.CreatedAt,
+		LastFetchedAt: nullTime(f.LastFetchedAt),
+	}
+}
+
+type follow struct {
+	FeedID     uuid.UUID `json:"feed_id"`
+	Feed       string    `json:"feed"`
+	URL        string    `json:"url"`
+	Tags       []string  `json:"tags"`
+	Unread     int64     `json:"unread"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (s *Server) listFeeds(w http.ResponseWriter, r *http.Request) error {
+	rows, err := s.db.ListFeeds(r.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %w", err)
+	}
+	feeds := make([]feed, len(rows))
+	for i, f := range rows {
+		feeds[i] = newFeed(f)
+	}
+	return writeJSON(w, http.StatusOK, map[string][]feed{"feeds": feeds})
+}
+
+// createFeed adds a feed owned by the user and follows it, like addfeed.
+func (s *Server) createFeed(w http.ResponseWriter, r *http.Request) error {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return err
+	}
+	var body struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := decodeJSON(w, r, &body); err != nil {
+		return err
+	}
+	body.Name, body.URL = strings.TrimSpace(body.Name), strings.TrimSpace(body.URL)
+	if body.Name == "" || body.URL == "" {
+		return errorf(http.StatusBadRequest, "name and url are required")
+	}
+
+	ctx := r.Context()
+	_, err = s.db.GetFeedByURL(ctx, body.URL)
+	if err == nil {
+		return errorf(http.StatusConflict, "feed %s already exists", body.URL)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check feed: %w", err)
+	}
+
+	now := time.Now()
+	var created database.Feed
+	err = s.db.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+		created, err = q.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      body.Name,
+			Url:       body.URL,
+			UserID:    u.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create feed: %w", err)
+		}
+		_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    u.ID,
+			FeedID:    created.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to follow feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, newFeed(created))
+}
+
+func (s *Server) listFollows(w http.ResponseWriter, r *http.Request) error {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return err
+	}
+	ctx := r.Context()
+	rows, err := s.db.GetFeedFollowsForUser(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get feed follows: %w", err)
+	}
+	tags, err := s.db.GetFeedFollowTagsForUser(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+	tagsByFollow := make(map[uuid.UUID][]string)
+	for _, t := range tags {
+		tagsByFollow[t.FeedFollowID] = append(tagsByFollow[t.FeedFollowID], t.TagName)
+	}
+
+	follows := make([]follow, len(rows))
+	for i, ff := range rows {
+		follows[i] = follow{
+			FeedID:     ff.FeedID,
+			Feed:       ff.FeedName,
+			URL:        ff.FeedUrl,
+			Tags:       tagsByFollow[ff.ID],
+			Unread:     ff.UnreadCount,
+			FollowedAt: ff.CreatedAt,
+		}
+		if follows[i].Tags == nil {
+			follows[i].Tags = []string{}
+		}
+	}
+	return writeJSON(w, http.StatusOK, map[string][]follow{"follows": follows})
+}
+
+func (s *Server) createFollow(w http.ResponseWriter, r *http.Request) error {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return err
+	}
+	var body struct {
+		URL string `json:"url"`
+	}
+	if err := decodeJSON(w, r, &body); err != nil {
+		return err
+	}
+	if body.URL == "" {
+		return errorf(http.StatusBadRequest, "url is required")
+	}
+
+	ctx := r.Context()
+	f, err := s.db.GetFeedByURL(ctx, body.URL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorf(http.StatusNotFound, "feed %s not found", body.URL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get feed: %w", err)
+	}
+	_, err = s.db.GetFeedFollowForUserByURL(ctx, database.GetFeedFollowForUserByURLParams{UserID: u.ID, Url: f.Url})
+	if err == nil {
+		return errorf(http.StatusConflict, "%s already follows %s", u.Name, f.Url)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check feed follow: %w", err)
+	}
+
+	now := time.Now()
+	ff, err := s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    u.ID,
+		FeedID:    f.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to follow feed: %w", err)
+	}
+	return writeJSON(w, http.StatusCreated, follow{
+		FeedID:     f.ID,
+		Feed:       f.Name,
+		URL:        f.Url,
+		Tags:       []string{},
+		FollowedAt: ff.CreatedAt,
+	})
+}
+
+// deleteFollow unfollows the feed given by the url query parameter, since
+// feed URLs don't fit in a path segment.
+func (s *Server) deleteFollow(w http.ResponseWriter, r *http.Request) error {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return err
+	}
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		return errorf(http.StatusBadRequest, "url query parameter is required")
+	}
+
+	ctx := r.Context()
+	_, err = s.db.GetFeedFollowForUserByURL(ctx, database.GetFeedFollowForUserByURLParams{UserID: u.ID, Url: url})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorf(http.StatusNotFound, "%s does not follow %s", u.Name, url)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get feed follow: %w", err)
+	}
+	if err := s.db.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{UserID: u.ID, Url: url}); err != nil {
+		return fmt.Errorf("failed to unfollow feed: %w", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gator/internal/cursor"
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPostLimit = 20
+	maxPostLimit     = 100
+)
+
+type post struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Author      string     `json:"author"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	Feed        string     `json:"feed"`
+	PublishedAt *time.Time `json:"published_at"`
+	FetchedAt   time.Time  `json:"fetched_at"`
+	Read        bool       `json:"read"`
+}
+
+type postPage struct {
+	Posts []post `json:"posts"`
+	// Next is passed as the after parameter to get the following page. It
+	// is omitted on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// listPosts supports the same filters as browse, as query parameters:
+// all, tag, feed, author, since, until, sort, limit, offset and after.
+func (s *Server) listPosts(w http.ResponseWriter, r *http.Request) error {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return err
+	}
+
+	q := r.URL.Query()
+	all, err := boolParam(q.Get("all"))
+	if err != nil {
+		return err
+	}
+	limit, err := intParam(q.Get("limit"), "limit", defaultPostLimit)
+	if err != nil {
+		return err
+	}
+	if limit < 1 || limit > maxPostLimit {
+		return errorf(http.StatusBadRequest, "limit must be between 1 and %d", maxPostLimit)
+	}
+	offset, err := intParam(q.Get("offset"), "offset", 0)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		return errorf(http.StatusBadRequest, "offset cannot be negative")
+	}
+
+	sortBy := q.Get("sort")
+	switch sortBy {
+	case "":
+		sortBy = "published"
+	case "published", "fetched", "feed":
+	default:
+		return errorf(http.StatusBadRequest, "invalid sort %q: must be published, fetched or feed", sortBy)
+	}
+
+	params := database.BrowsePostsForUserParams{
+		UserID:     u.ID,
+		UnreadOnly: !all,
+		Tag:        nullString(q.Get("tag")),
+		FeedUrl:    nullString(q.Get("feed")),
+		Author:     nullString(q.Get("author")),
+		Sort:       sortBy,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+	if params.Since, err = dateParam(q.Get("since"), "since"); err != nil {
+		return err
+	}
+	if params.Until, err = dateParam(q.Get("until"), "until"); err != nil {
+		return err
+	}
+	if after := q.Get("after"); after != "" {
+		if offset != 0 {
+			return errorf(http.StatusBadRequest, "after and offset cannot be used together")
+		}
+		c, err := cursor.Decode(after)
+		if err != nil {
+			return errorf(http.StatusBadRequest, "invalid after cursor")
+		}
+		c.Apply(&params)
+	}
+
+	rows, err := s.db.BrowsePostsForUser(r.Context(), params)
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	page := postPage{Posts: make([]post, len(rows))}
+	for i, p := range rows {
+		page.Posts[i] = post{
+			ID:          p.ID,
+			Title:       p.Title,
+			URL:         p.Url,
+			Description: p.Description.String,
+			Author:      p.Author.String,
+			FeedID:      p.FeedID,
+			Feed:        p.FeedName,
+			PublishedAt: nullTime(p.PublishedAt),
+			FetchedAt:   p.CreatedAt,
+			Read:        p.ReadAt.Valid,
+		}
+	}
+	if len(rows) == limit {
+		page.Next = cursor.After(rows[len(rows)-1], sortBy).Encode()
+	}
+	return writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) markRead(w http.ResponseWriter, r *http.Request) error {
+	u, p, err := s.pathPost(r)
+	if err != nil {
+		return err
+	}
+	err = s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{UserID: u.ID, PostID: p.ID})
+	if err != nil {
+		return fmt.Errorf("failed to mark post read: %w", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) markUnread(w http.ResponseWriter, r *http.Request) error {
+	u, p, err := s.pathPost(r)
+	if err != nil {
+		return err
+	}
+	err = s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{UserID: u.ID, PostID: p.ID})
+	if err != nil {
+		return fmt.Errorf("failed to mark post unread: %w", err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// pathPost loads the user and post named by the {name} and {id} path
+// segments.
+func (s *Server) pathPost(r *http.Request) (database.User, database.Post, error) {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return u, database.Post{}, err
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return u, database.Post{}, errorf(http.StatusBadRequest, "invalid post id %q", r.PathValue("id"))
+	}
+	p, err := s.db.GetPostByID(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return u, p, errorf(http.StatusNotFound, "post %s not found", id)
+	}
+	if err != nil {
+		return u, p, fmt.Errorf("failed to get post: %w", err)
+	}
+	return u, p, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func boolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errorf(http.StatusBadRequest, "invalid boolean %q", value)
+	}
+	return b, nil
+}
+
+func intParam(value, name string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid %s %q: must be a number", name, value)
+	}
+	return n, nil
+}
+
+// dateParam parses a YYYY-MM-DD or RFC3339 date, as accepted by browse.
+func dateParam(value, name string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return sql.NullTime{Time: t, Valid: true}, nil
+		}
+	}
+	return sql.NullTime{}, errorf(http.StatusBadRequest, "invalid %s %q: expected YYYY-MM-DD or RFC3339", name, value)
+}
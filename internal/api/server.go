@@ -0,0 +1,263 @@
+// Package api serves gator's data as a JSON REST API.
+//
+// Every request must carry an API key created with gator apikey create:
+//
+//	Authorization: Bearer gator_...
+//
+// Routes under /api/users/{name}/ only accept the named user's own keys.
+//
+// The user's timeline is also served as RSS and Atom feeds under
+// /feeds/{name}/, and NewEventServer streams new posts as Server-Sent
+// Events. Feed readers and browsers' EventSource can't usually send
+// headers, so those routes also accept a key query parameter, which is
+// left out of the request log. It only accepts feed tokens, read-only keys
+// created with gator apikey create --feed-token, so that a full key never
+// has to appear in a URL.
+//
+// Every response is JSON. Failures use a single shape with the HTTP status
+// repeated in the body:
+//
+//	{"error": {"status": 404, "message": "user bob not found"}}
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gator/internal/auth"
+	"gator/internal/database"
+	"gator/internal/events"
+)
+
+// maxBodySize limits request bodies; every request body is a small JSON
+// object.
+const maxBodySize = 1 << 20
+
+type Server struct {
+	db     database.Store
+	logger *slog.Logger
+	mux    *http.ServeMux
+	bus    *events.Bus
+}
+
+func NewServer(db database.Store, logger *slog.Logger) *Server {
+	s := &Server{
+		db:     db,
+		logger: logger,
+		mux:    http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.handle("GET /api/users", s.listUsers)
+	s.handle("POST /api/users", s.createUser)
+	s.handle("GET /api/users/{name}", s.getUser)
+
+	s.handle("GET /api/feeds", s.listFeeds)
+	s.handle("POST /api/users/{name}/feeds", s.createFeed)
+
+	s.handle("GET /api/users/{name}/follows", s.listFollows)
+	s.handle("POST /api/users/{name}/follows", s.createFollow)
+	s.handle("DELETE /api/users/{name}/follows", s.deleteFollow)
+
+	s.handle("GET /api/users/{name}/posts", s.listPosts)
+	s.handle("PUT /api/users/{name}/posts/{id}/read", s.markRead)
+	s.handle("DELETE /api/users/{name}/posts/{id}/read", s.markUnread)
+
+	s.handleKeyParam("GET /feeds/{name}/rss", s.rssFeed)
+	s.handleKeyParam("GET /feeds/{name}/atom", s.atomFeed)
+}
+
+// ServeHTTP logs every request and answers unknown routes with the same
+// JSON errors as the handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	if h, pattern := s.mux.Handler(r); pattern == "" {
+		// The mux has no route for this request; find out whether it would
+		// answer 404 or 405 and reply in JSON instead.
+		probe := &statusRecorder{ResponseWriter: discardWriter{header: rec.Header()}, status: http.StatusNotFound}
+		h.ServeHTTP(probe, r)
+		writeError(rec, &Error{Status: probe.status, Message: http.StatusText(probe.status)})
+	} else {
+		s.mux.ServeHTTP(rec, r)
+	}
+
+	s.logger.Info("request",
+		"method", r.Method,
+		"uri", logURI(r.URL),
+		"status", rec.status,
+		"duration", time.Since(start).Round(time.Microsecond),
+	)
+}
+
+// logURI is the request URI with any API key in the query hidden.
+func logURI(u *url.URL) string {
+	q := u.Query()
+	if !q.Has("key") {
+		return u.RequestURI()
+	}
+	q.Set("key", "REDACTED")
+	redacted := *u
+	redacted.RawQuery = q.Encode()
+	return redacted.RequestURI()
+}
+
+// handlerFunc is an HTTP handler that reports failures by returning an
+// error instead of writing the response itself.
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, s.wrap(h, false))
+}
+
+// handleKeyParam is like handle, but also accepts the API key as a key
+// query parameter.
+func (s *Server) handleKeyParam(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, s.wrap(h, true))
+}
+
+func (s *Server) wrap(h handlerFunc, keyInQuery bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := s.authenticate(r, keyInQuery)
+		if err == nil {
+			r = r.WithContext(context.WithValue(r.Context(), userKey{}, user))
+			err = h(w, r)
+		}
+		if err == nil {
+			return
+		}
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			s.logger.Error("request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+			apiErr = &Error{Status: http.StatusInternalServerError, Message: "internal server error"}
+		}
+		if apiErr.Status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gator"`)
+		}
+		writeError(w, apiErr)
+	}
+}
+
+type userKey struct{}
+
+// authenticate returns the owner of the request's bearer token, which must
+// be a full API key, or, if keyInQuery is set and there is no Authorization
+// header, of the key query parameter, which must be a feed token.
+func (s *Server) authenticate(r *http.Request, keyInQuery bool) (database.User, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	scope := auth.ScopeFull
+	if keyInQuery && r.Header.Get("Authorization") == "" {
+		token, ok = r.URL.Query().Get("key"), true
+		scope = auth.ScopeFeeds
+	}
+	if !ok || token == "" {
+		return database.User{}, errorf(http.StatusUnauthorized, "missing API key")
+	}
+	user, err := auth.Authenticate(r.Context(), s.db, strings.TrimSpace(token), scope)
+	switch {
+	case errors.Is(err, auth.ErrInvalidKey):
+		return user, errorf(http.StatusUnauthorized, "invalid API key")
+	case errors.Is(err, auth.ErrWrongScope) && scope == auth.ScopeFeeds:
+		return user, errorf(http.StatusUnauthorized, "the key parameter only accepts feed tokens: create one with gator apikey create --feed-token")
+	case errors.Is(err, auth.ErrWrongScope):
+		return user, errorf(http.StatusForbidden, "feed tokens can only be used as the key parameter of feed URLs")
+	}
+	return user, err
+}
+
+// Error is a failure that is safe to show to API clients. Any other error
+// returned by a handler is logged and reported as a 500.
+type Error struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(status int, format string, args ...any) *Error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err *Error) {
+	writeJSON(w, err.Status, map[string]*Error{"error": err})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+// decodeJSON reads a JSON request body into v, rejecting unknown fields so
+// typos don't go unnoticed.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// requestUser returns the owner of the request's API key.
+func requestUser(r *http.Request) database.User {
+	return r.Context().Value(userKey{}).(database.User)
+}
+
+// pathUser returns the user named in the {name} path segment, who must be
+// the owner of the request's API key.
+func (s *Server) pathUser(r *http.Request) (database.User, error) {
+	user := requestUser(r)
+	if name := r.PathValue("name"); name != user.Name {
+		return user, errorf(http.StatusForbidden, "API key does not belong to %s", name)
+	}
+	return user, nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for
+// example to flush a stream.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// discardWriter swallows a response, keeping only its headers.
+type discardWriter struct {
+	header http.Header
+}
+
+func (d discardWriter) Header() http.Header         { return d.header }
+func (d discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d discardWriter) WriteHeader(int)             {}
+
+// nullTime returns a pointer to t's time, or nil if t is NULL, so missing
+// timestamps encode as null.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,507 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gator/internal/auth"
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+	"gator/internal/database/memory"
+
+	"github.com/google/uuid"
+)
+
+// testServer is an API server over a store holding alice and bob, each with
+// an API key.
+type testServer struct {
+	*httptest.Server
+	db           database.Store
+	alice, bob   database.User
+	aliceKey     string
+	bobKey       string
+	feedA, feedB database.Feed
+	followA      database.CreateFeedFollowRow
+	followB      database.CreateFeedFollowRow
+}
+
+var base = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T, db database.Store) *testServer {
+	t.Helper()
+	ctx := context.Background()
+	ts := &testServer{db: db}
+	ts.alice, ts.aliceKey = createUser(t, db, "alice")
+	ts.bob, ts.bobKey = createUser(t, db, "bob")
+
+	createFeed := func(name, url string) database.Feed {
+		feed, err := db.CreateFeed(ctx, database.CreateFeedParams{
+			ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Name: name, Url: url, UserID: ts.bob.ID,
+		})
+		if err != nil {
+			t.Fatalf("CreateFeed: %v", err)
+		}
+		return feed
+	}
+	follow := func(feed database.Feed) database.CreateFeedFollowRow {
+		ff, err := db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: ts.alice.ID, FeedID: feed.ID,
+		})
+		if err != nil {
+			t.Fatalf("CreateFeedFollow: %v", err)
+		}
+		return ff
+	}
+	ts.feedA = createFeed("A", "https://a.example.com/rss")
+	ts.feedB = createFeed("B", "https://b.example.com/rss")
+	ts.followA = follow(ts.feedA)
+	ts.followB = follow(ts.feedB)
+
+	tag, err := db.CreateTag(ctx, database.CreateTagParams{
+		ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: ts.alice.ID, Name: "news",
+	})
+	if err != nil {
+		t.Fatalf("CreateTag: %v", err)
+	}
+	err = db.AddFeedFollowTag(ctx, database.AddFeedFollowTagParams{FeedFollowID: ts.followB.ID, TagID: tag.ID})
+	if err != nil {
+		t.Fatalf("AddFeedFollowTag: %v", err)
+	}
+
+	// p1 to p5 alternate between the feeds, one day apart; p1 is read.
+	for i := range 5 {
+		feed := ts.feedA
+		if i%2 == 1 {
+			feed = ts.feedB
+		}
+		published := base.AddDate(0, 0, i)
+		p := database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   published,
+			UpdatedAt:   published,
+			Title:       fmt.Sprintf("p%d", i+1),
+			Url:         fmt.Sprintf("https://example.com/%d", i+1),
+			PublishedAt: sql.NullTime{Time: published, Valid: true},
+			FeedID:      feed.ID,
+			Author:      sql.NullString{String: []string{"Ann", "Ben"}[i%2], Valid: true},
+		}
+		if _, err := db.CreatePost(ctx, p); err != nil {
+			t.Fatalf("CreatePost: %v", err)
+		}
+		if i == 0 {
+			if err := db.MarkPostRead(ctx, database.MarkPostReadParams{UserID: ts.alice.ID, PostID: p.ID}); err != nil {
+				t.Fatalf("MarkPostRead: %v", err)
+			}
+		}
+	}
+
+	ts.Server = httptest.NewServer(NewServer(db, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func createUser(t *testing.T, db database.Store, name string) (database.User, string) {
+	t.Helper()
+	user, err := db.CreateUser(context.Background(), database.CreateUserParams{ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Name: name})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user, createKey(t, db, user, auth.ScopeFull)
+}
+
+// createKey returns the secret of a new key for user with the given scope.
+func createKey(t *testing.T, db database.Store, user database.User, scope string) string {
+	t.Helper()
+	key, err := auth.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.CreateAPIKey(context.Background(), database.CreateAPIKeyParams{
+		ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: user.ID, Name: "test", Prefix: key.Prefix, KeyHash: key.Hash, Scope: scope,
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	return key.Secret
+}
+
+// do sends a request with key as the bearer token, if it is not empty, and
+// returns the response with its body read.
+func (ts *testServer) do(t *testing.T, method, path, key string) (*http.Response, []byte) {
+	t.Helper()
+	return ts.send(t, method, path, key, "")
+}
+
+// send is do with a JSON request body.
+func (ts *testServer) send(t *testing.T, method, path, key, payload string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, body
+}
+
+// checkError fails t unless the response is a JSON error with the given
+// status whose message contains message.
+func checkError(t *testing.T, resp *http.Response, body []byte, status int, message string) {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Errorf("status = %d, want %d; body %s", resp.StatusCode, status, body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got struct {
+		Error *Error `json:"error"`
+	}
+	dec := json.NewDecoder(strings.NewReader(string(body)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&got); err != nil || got.Error == nil {
+		t.Fatalf("body %s is not a JSON error: %v", body, err)
+	}
+	if got.Error.Status != status {
+		t.Errorf("error.status = %d, want %d", got.Error.Status, status)
+	}
+	if !strings.Contains(got.Error.Message, message) {
+		t.Errorf("error.message = %q, want it to contain %q", got.Error.Message, message)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := newTestServer(t, memory.New())
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		key     string
+		status  int
+		message string
+	}{
+		{"no key", "GET", "/api/users", "", http.StatusUnauthorized, "missing API key"},
+		{"invalid key", "GET", "/api/users", "gator_nope", http.StatusUnauthorized, "invalid API key"},
+		{"key in query", "GET", "/api/users?key=" + ts.aliceKey, "", http.StatusUnauthorized, "missing API key"},
+		{"other user's posts", "GET", "/api/users/bob/posts", ts.aliceKey, http.StatusForbidden, "does not belong to bob"},
+		{"other user's follows", "GET", "/api/users/alice/follows", ts.bobKey, http.StatusForbidden, "does not belong to alice"},
+		{"unknown user", "GET", "/api/users/carol", ts.aliceKey, http.StatusNotFound, "user carol not found"},
+		{"unknown post", "PUT", "/api/users/alice/posts/" + uuid.NewString() + "/read", ts.aliceKey, http.StatusNotFound, "not found"},
+		{"invalid post id", "PUT", "/api/users/alice/posts/nope/read", ts.aliceKey, http.StatusBadRequest, "invalid post id"},
+		{"unknown route", "GET", "/api/nope", ts.aliceKey, http.StatusNotFound, "Not Found"},
+		{"unknown route without key", "GET", "/nope", "", http.StatusNotFound, "Not Found"},
+		{"wrong method", "PATCH", "/api/users", ts.aliceKey, http.StatusMethodNotAllowed, "Method Not Allowed"},
+		{"wrong method on posts", "DELETE", "/api/users/alice/posts", "", http.StatusMethodNotAllowed, "Method Not Allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := ts.do(t, tt.method, tt.path, tt.key)
+			checkError(t, resp, body, tt.status, tt.message)
+			if tt.status == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+			if tt.status == http.StatusMethodNotAllowed && resp.Header.Get("Allow") == "" {
+				t.Error("405 without Allow")
+			}
+		})
+	}
+}
+
+func TestFeedTokens(t *testing.T) {
+	ts := newTestServer(t, memory.New())
+	aliceToken := createKey(t, ts.db, ts.alice, auth.ScopeFeeds)
+	bobToken := createKey(t, ts.db, ts.bob, auth.ScopeFeeds)
+
+	for _, path := range []string{"/feeds/alice/rss", "/feeds/alice/atom"} {
+		t.Run(path, func(t *testing.T) {
+			resp, body := ts.do(t, "GET", path+"?key="+url.QueryEscape(aliceToken), "")
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("feed token in key: status %d, body %s", resp.StatusCode, body)
+			}
+			resp, body = ts.do(t, "GET", path, ts.aliceKey)
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("API key in header: status %d, body %s", resp.StatusCode, body)
+			}
+
+			resp, body = ts.do(t, "GET", path, "")
+			checkError(t, resp, body, http.StatusUnauthorized, "missing API key")
+			resp, body = ts.do(t, "GET", path+"?key="+url.QueryEscape(ts.aliceKey), "")
+			checkError(t, resp, body, http.StatusUnauthorized, "only accepts feed tokens")
+			resp, body = ts.do(t, "GET", path+"?key="+url.QueryEscape(bobToken), "")
+			checkError(t, resp, body, http.StatusForbidden, "does not belong to alice")
+			resp, body = ts.do(t, "GET", path, aliceToken)
+			checkError(t, resp, body, http.StatusForbidden, "feed tokens can only be used")
+		})
+	}
+
+	// A feed token can't be used for anything else.
+	resp, body := ts.do(t, "GET", "/api/users/alice/posts", aliceToken)
+	checkError(t, resp, body, http.StatusForbidden, "feed tokens can only be used")
+}
+
+func TestTimelineFeeds(t *testing.T) {
+	ts := newTestServer(t, memory.New())
+	token := createKey(t, ts.db, ts.alice, auth.ScopeFeeds)
+	const title = `Tom & Jerry <3 "quotes"`
+	const description = `<p>Fish & chips</p>`
+	published := base.AddDate(0, 0, 10)
+	_, err := ts.db.CreatePost(context.Background(), database.CreatePostParams{
+		ID:          uuid.New(),
+		CreatedAt:   published,
+		UpdatedAt:   published,
+		Title:       title,
+		Url:         "https://example.com/tom?a=1&b=2",
+		Description: sql.NullString{String: description, Valid: true},
+		PublishedAt: sql.NullTime{Time: published, Valid: true},
+		FeedID:      ts.feedA.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+
+	// Both formats are decoded into the fields that hold the post.
+	type item struct {
+		Title, Link, Description string
+	}
+	tests := []struct {
+		path   string
+		decode func([]byte) (item, error)
+	}{
+		{"/feeds/alice/rss", func(body []byte) (item, error) {
+			var doc struct {
+				Items []struct {
+					Title       string `xml:"title"`
+					Link        string `xml:"link"`
+					Description string `xml:"description"`
+				} `xml:"channel>item"`
+			}
+			if err := xml.Unmarshal(body, &doc); err != nil || len(doc.Items) == 0 {
+				return item{}, err
+			}
+			return item(doc.Items[0]), nil
+		}},
+		{"/feeds/alice/atom", func(body []byte) (item, error) {
+			var doc struct {
+				Entries []struct {
+					Title string `xml:"title"`
+					Link  struct {
+						Href string `xml:"href,attr"`
+					} `xml:"link"`
+					Summary string `xml:"summary"`
+				} `xml:"entry"`
+			}
+			if err := xml.Unmarshal(body, &doc); err != nil || len(doc.Entries) == 0 {
+				return item{}, err
+			}
+			e := doc.Entries[0]
+			return item{Title: e.Title, Link: e.Link.Href, Description: e.Summary}, nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			get := func(etag string) (*http.Response, []byte) {
+				t.Helper()
+				req, err := http.NewRequest("GET", ts.URL+tt.path+"?key="+url.QueryEscape(token), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if etag != "" {
+					req.Header.Set("If-None-Match", etag)
+				}
+				resp, err := ts.Client().Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return resp, body
+			}
+
+			resp, body := get("")
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, body %s", resp.StatusCode, body)
+			}
+			if !strings.Contains(string(body), "Tom &amp; Jerry &lt;3") {
+				t.Errorf("title is not escaped:\n%s", body)
+			}
+			got, err := tt.decode(body)
+			if err != nil {
+				t.Fatalf("feed is not well-formed XML: %v\n%s", err, body)
+			}
+			want := item{Title: title, Link: "https://example.com/tom?a=1&b=2", Description: description}
+			if got != want {
+				t.Errorf("newest entry = %+v, want %+v", got, want)
+			}
+
+			etag := resp.Header.Get("ETag")
+			if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 3 {
+				t.Errorf("ETag = %q, want a quoted tag", etag)
+			}
+			if cc := resp.Header.Get("Cache-Control"); cc != "private, max-age=300" {
+				t.Errorf("Cache-Control = %q, want private, max-age=300", cc)
+			}
+			if resp.Header.Get("Last-Modified") == "" {
+				t.Error("no Last-Modified header")
+			}
+
+			resp, body = get(etag)
+			if resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+				t.Errorf("If-None-Match with the ETag: status %d, %d bytes, want 304 and no body", resp.StatusCode, len(body))
+			}
+			resp, _ = get(`"stale"`)
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("If-None-Match with another ETag: status %d, want 200", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestListPosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"unread by default", "", []string{"p5", "p4", "p3", "p2"}},
+		{"all", "all=true", []string{"p5", "p4", "p3", "p2", "p1"}},
+		{"feed", "all=1&feed=https://a.example.com/rss", []string{"p5", "p3", "p1"}},
+		{"tag", "tag=news", []string{"p4", "p2"}},
+		{"author", "all=true&author=ann", []string{"p5", "p3", "p1"}},
+		{"since and until", "all=true&since=2025-01-02&until=2025-01-04", []string{"p3", "p2"}},
+		{"sort fetched", "sort=fetched&limit=2", []string{"p5", "p4"}},
+		{"sort feed", "all=true&sort=feed", []string{"p5", "p3", "p1", "p4", "p2"}},
+		{"limit and offset", "all=true&limit=2&offset=1", []string{"p4", "p3"}},
+	}
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ts := newTestServer(t, db)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page := ts.listPosts(t, tt.query)
+				if got := titles(page); !slices.Equal(got, tt.want) {
+					t.Errorf("?%s = %v, want %v", tt.query, got, tt.want)
+				}
+			})
+		}
+
+		t.Run("pages", func(t *testing.T) {
+			var got []string
+			query := "all=true&limit=2"
+			for pages := 0; ; pages++ {
+				if pages > 3 {
+					t.Fatalf("too many pages: %v", got)
+				}
+				page := ts.listPosts(t, query)
+				got = append(got, titles(page)...)
+				if page.Next == "" {
+					break
+				}
+				query = "all=true&limit=2&after=" + url.QueryEscape(page.Next)
+			}
+			if want := []string{"p5", "p4", "p3", "p2", "p1"}; !slices.Equal(got, want) {
+				t.Errorf("pages = %v, want %v", got, want)
+			}
+		})
+
+		for _, tt := range []struct{ query, message string }{
+			{"limit=0", "limit must be between"},
+			{"limit=101", "limit must be between"},
+			{"limit=x", "invalid limit"},
+			{"offset=-1", "offset cannot be negative"},
+			{"all=maybe", "invalid boolean"},
+			{"sort=random", "invalid sort"},
+			{"since=yesterday", "invalid since"},
+			{"after=nope", "invalid after cursor"},
+			{"after=nope&offset=1", "cannot be used together"},
+		} {
+			t.Run(tt.query, func(t *testing.T) {
+				resp, body := ts.do(t, "GET", "/api/users/alice/posts?"+tt.query, ts.aliceKey)
+				checkError(t, resp, body, http.StatusBadRequest, tt.message)
+			})
+		}
+	})
+}
+
+func (ts *testServer) listPosts(t *testing.T, query string) postPage {
+	t.Helper()
+	resp, body := ts.do(t, "GET", "/api/users/alice/posts?"+query, ts.aliceKey)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("?%s: status %d, body %s", query, resp.StatusCode, body)
+	}
+	var page postPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("invalid JSON %s: %v", body, err)
+	}
+	return page
+}
+
+func titles(page postPage) []string {
+	var out []string
+	for _, p := range page.Posts {
+		out = append(out, p.Title)
+	}
+	return out
+}
+
+func TestCreateUser(t *testing.T) {
+	ts := newTestServer(t, memory.New())
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+	}{
+		{"no password", `{"name": "carol"}`, http.StatusBadRequest, "password is required"},
+		{"short password", `{"name": "carol", "password": "short"}`, http.StatusBadRequest, "at least 8 characters"},
+		{"no name", `{"password": "correct horse"}`, http.StatusBadRequest, "name is required"},
+		{"existing name", `{"name": "alice", "password": "correct horse"}`, http.StatusConflict, "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := ts.send(t, "POST", "/api/users", ts.aliceKey, tt.body)
+			checkError(t, resp, body, tt.status, tt.message)
+		})
+	}
+	if _, err := ts.db.GetUser(context.Background(), "carol"); err != sql.ErrNoRows {
+		t.Errorf("GetUser(carol) after rejected requests: got %v, want sql.ErrNoRows", err)
+	}
+
+	resp, body := ts.send(t, "POST", "/api/users", ts.aliceKey, `{"name": "carol", "password": "correct horse"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body %s", resp.StatusCode, body)
+	}
+	carol, err := ts.db.GetUser(context.Background(), "carol")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if !auth.CheckPassword(carol.PasswordHash.String, "correct horse") {
+		t.Error("carol's password hash does not match the password given at creation")
+	}
+}
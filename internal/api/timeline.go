@@ -0,0 +1,287 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// defaultTimelineLimit is how many posts a generated feed holds unless the
+// limit parameter says otherwise.
+const defaultTimelineLimit = 50
+
+// timelineMaxAge is how long feed readers may cache a generated feed
+// before asking again. Later requests are cheap thanks to the ETag.
+const timelineMaxAge = 5 * time.Minute
+
+// timeline is the data shared by the RSS and Atom renderings of a user's
+// posts.
+type timeline struct {
+	title   string
+	self    string
+	user    database.User
+	updated time.Time
+	posts   []database.Post
+	feeds   map[uuid.UUID]database.GetFeedFollowsForUserRow
+}
+
+// loadTimeline reads the newest posts from the user's follows, optionally
+// limited to follows with the tag query parameter.
+func (s *Server) loadTimeline(r *http.Request) (*timeline, error) {
+	u, err := s.pathUser(r)
+	if err != nil {
+		return nil, err
+	}
+	q := r.URL.Query()
+	limit, err := intParam(q.Get("limit"), "limit", defaultTimelineLimit)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > maxPostLimit {
+		return nil, errorf(http.StatusBadRequest, "limit must be between 1 and %d", maxPostLimit)
+	}
+
+	ctx := r.Context()
+	tag := q.Get("tag")
+	posts, err := s.db.GetPostsForUSer(ctx, database.GetPostsForUSerParams{
+		UserID: u.ID,
+		Tag:    nullString(tag),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts: %w", err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(ctx, u.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed follows: %w", err)
+	}
+
+	t := &timeline{
+		title: "gator: " + u.Name,
+		self:  selfURL(r),
+		user:  u,
+		posts: posts,
+		feeds: make(map[uuid.UUID]database.GetFeedFollowsForUserRow, len(follows)),
+	}
+	if tag != "" {
+		t.title += " (" + tag + ")"
+	}
+	for _, ff := range follows {
+		t.feeds[ff.FeedID] = ff
+	}
+	// Use the newest fetch time rather than the current time so that the
+	// document, and with it the ETag, only changes when the posts do.
+	t.updated = u.CreatedAt
+	for _, p := range posts {
+		if p.CreatedAt.After(t.updated) {
+			t.updated = p.CreatedAt
+		}
+	}
+	return t, nil
+}
+
+// selfURL rebuilds the URL a feed was requested with, leaving out the API
+// key so it is never written into the document.
+func selfURL(r *http.Request) string {
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+	q := r.URL.Query()
+	q.Del("key")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// postTime is when a post was published, or fetched if the feed didn't
+// say.
+func postTime(p database.Post) time.Time {
+	if p.PublishedAt.Valid {
+		return p.PublishedAt.Time
+	}
+	return p.CreatedAt
+}
+
+type rssDoc struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	GUID        rssGUID   `xml:"guid"`
+	Description string    `xml:"description,omitempty"`
+	Creator     string    `xml:"dc:creator,omitempty"`
+	PubDate     string    `xml:"pubDate"`
+	Source      rssSource `xml:"source"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssSource struct {
+	URL  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+// rssFeed serves the user's timeline as RSS 2.0.
+func (s *Server) rssFeed(w http.ResponseWriter, r *http.Request) error {
+	t, err := s.loadTimeline(r)
+	if err != nil {
+		return err
+	}
+	doc := rssDoc{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         t.title,
+			Link:          t.self,
+			Description:   "Posts from the feeds " + t.user.Name + " follows",
+			LastBuildDate: t.updated.UTC().Format(time.RFC1123Z),
+			Generator:     "gator",
+			Self:          atomLink{Href: t.self, Rel: "self", Type: "application/rss+xml"},
+			Items:         make([]rssItem, len(t.posts)),
+		},
+	}
+	for i, p := range t.posts {
+		f := t.feeds[p.FeedID]
+		doc.Channel.Items[i] = rssItem{
+			Title:       p.Title,
+			Link:        p.Url,
+			GUID:        rssGUID{IsPermaLink: true, Value: p.Url},
+			Description: p.Description.String,
+			Creator:     p.Author.String,
+			PubDate:     postTime(p).UTC().Format(time.RFC1123Z),
+			Source:      rssSource{URL: f.FeedUrl, Name: f.FeedName},
+		}
+	}
+	return writeXML(w, r, "application/rss+xml; charset=utf-8", t.updated, doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      atomLink    `xml:"link"`
+	Published string      `xml:"published,omitempty"`
+	Updated   string      `xml:"updated"`
+	Author    *atomPerson `xml:"author"`
+	Summary   *atomText   `xml:"summary"`
+	Source    atomSource  `xml:"source"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomSource struct {
+	Title string   `xml:"title"`
+	Link  atomLink `xml:"link"`
+}
+
+// atomFeed serves the user's timeline as an Atom feed.
+func (s *Server) atomFeed(w http.ResponseWriter, r *http.Request) error {
+	t, err := s.loadTimeline(r)
+	if err != nil {
+		return err
+	}
+	doc := atomFeed{
+		Title:   t.title,
+		ID:      t.self,
+		Updated: t.updated.UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: t.self, Rel: "self", Type: "application/atom+xml"},
+		Author:  atomPerson{Name: t.user.Name},
+		Entries: make([]atomEntry, len(t.posts)),
+	}
+	for i, p := range t.posts {
+		f := t.feeds[p.FeedID]
+		e := atomEntry{
+			Title:   p.Title,
+			ID:      "urn:uuid:" + p.ID.String(),
+			Link:    atomLink{Href: p.Url, Rel: "alternate"},
+			Updated: postTime(p).UTC().Format(time.RFC3339),
+			Source:  atomSource{Title: f.FeedName, Link: atomLink{Href: f.FeedUrl, Rel: "self"}},
+		}
+		if p.PublishedAt.Valid {
+			e.Published = e.Updated
+		}
+		if p.Author.Valid {
+			e.Author = &atomPerson{Name: p.Author.String}
+		}
+		if p.Description.Valid {
+			// Feed descriptions are usually HTML; type="html" tells readers
+			// to unescape and render it.
+			e.Summary = &atomText{Type: "html", Value: p.Description.String}
+		}
+		doc.Entries[i] = e
+	}
+	return writeXML(w, r, "application/atom+xml; charset=utf-8", t.updated, doc)
+}
+
+// writeXML encodes doc and serves it with caching headers. The ETag is a
+// hash of the document, so http.ServeContent can answer If-None-Match and
+// If-Modified-Since with 304 Not Modified.
+func writeXML(w http.ResponseWriter, r *http.Request, contentType string, modified time.Time, doc any) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	buf.WriteByte('\n')
+
+	sum := sha256.Sum256(buf.Bytes())
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	h.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(timelineMaxAge.Seconds())))
+	http.ServeContent(w, r, "", modified, bytes.NewReader(buf.Bytes()))
+	return nil
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gator/internal/auth"
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type user struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"created_at"`
+	FollowCount  int64      `json:"follow_count"`
+	FeedCount    int64      `json:"feed_count"`
+	LastActiveAt *time.Time `json:"last_active_at"`
+}
+
+func newUser(u database.GetUserStatsRow) user {
+	out := user{
+		ID:          u.ID,
+		Name:        u.Name,
+		CreatedAt:   u.CreatedAt,
+		FollowCount: u.FollowCount,
+		FeedCount:   u.FeedCount,
+	}
+	if !u.LastActiveAt.IsZero() {
+		out.LastActiveAt = &u.LastActiveAt
+	}
+	return out
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) error {
+	stats, err := s.db.GetUserStats(r.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	users := make([]user, len(stats))
+	for i, u := range stats {
+		users[i] = newUser(u)
+	}
+	return writeJSON(w, http.StatusOK, map[string][]user{"users": users})
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) error {
+	name := r.PathValue("name")
+	stats, err := s.db.GetUserStats(r.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, u := range stats {
+		if u.Name == name {
+			return writeJSON(w, http.StatusOK, newUser(u))
+		}
+	}
+	return errorf(http.StatusNotFound, "user %s not found", name)
+}
+
+// createUser registers a user. Email is optional but a password is not, as
+// gator login lets users without one in with no credentials.
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) error {
+	var body struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := decodeJSON(w, r, &body); err != nil {
+		return err
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		return errorf(http.StatusBadRequest, "name is required")
+	}
+
+	ctx := r.Context()
+	_, err := s.db.GetUser(ctx, name)
+	if err == nil {
+		return errorf(http.StatusConflict, "user %s already exists", name)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check username: %w", err)
+	}
+
+	var email string
+	if body.Email != "" {
+		parsed, err := mail.ParseAddress(body.Email)
+		if err != nil {
+			return errorf(http.StatusBadRequest, "invalid email %q", body.Email)
+		}
+		email = strings.ToLower(parsed.Address)
+		_, err = s.db.GetUserByEmail(ctx, nullString(email))
+		if err == nil {
+			return errorf(http.StatusConflict, "email %s is already in use", email)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check email: %w", err)
+		}
+	}
+	if body.Password == "" {
+		return errorf(http.StatusBadRequest, "password is required")
+	}
+	if len(body.Password) < auth.MinPasswordLen {
+		return errorf(http.StatusBadRequest, "password must be at least %d characters", auth.MinPasswordLen)
+	}
+	hash, err := auth.HashPassword(body.Password)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+
+	now := time.Now()
+	created, err := s.db.CreateUser(ctx, database.CreateUserParams{
+		ID:           uuid.New(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		Name:         name,
+		Email:        nullString(email),
+		PasswordHash: nullString(hash),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return writeJSON(w, http.StatusCreated, user{ID: created.ID, Name: created.Name, CreatedAt: created.CreatedAt})
+}
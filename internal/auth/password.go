@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLen is the shortest password accepted by register and passwd.
+const MinPasswordLen = 8
+
+// HashPassword returns a bcrypt hash of password for storing in
+// users.password_hash.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLen {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLen)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("password must be at most 72 bytes")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches a hash made by
+// HashPassword.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
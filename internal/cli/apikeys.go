@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gator/internal/auth"
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func APIKeyFlags(fs *flag.FlagSet) {
+	fs.String("name", "", "a `label` to tell the key apart from your others")
+	fs.Bool("feed-token", false, "create a read-only token for the key parameter of your RSS, Atom and event stream URLs")
+}
+
+type apiKeyRow struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func HandlerAPIKeyLogged(s *State, cmd Command, user database.User) error {
+	ctx := s.Ctx
+
+	switch cmd.Args[0] {
+	case "create":
+		scope := auth.ScopeFull
+		if cmd.boolFlag("feed-token") {
+			scope = auth.ScopeFeeds
+		}
+		key, err := createAPIKey(ctx, s.DB, user, cmd.stringFlag("name"), scope)
+		if err != nil {
+			return err
+		}
+		fmt.Println(key.Secret)
+		if scope == auth.ScopeFeeds {
+			fmt.Fprintf(os.Stderr, "Created a feed token for %s. Add it to feed URLs as ?key=...; it is shown only once.\n", user.Name)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "Created an API key for %s. It is shown only once; store it somewhere safe.\n", user.Name)
+		return nil
+
+	case "list":
+		out, err := cmd.renderer()
+		if err != nil {
+			return err
+		}
+		keys, err := s.DB.ListAPIKeysForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get API keys: %w", err)
+		}
+		if len(keys) == 0 {
+			out.Notef("No API keys found.")
+		}
+		rows := make([]apiKeyRow, len(keys))
+		for i, k := range keys {
+			rows[i] = apiKeyRow{
+				ID:         k.ID,
+				Name:       k.Name,
+				Prefix:     k.Prefix,
+				Scope:      k.Scope,
+				CreatedAt:  k.CreatedAt,
+				LastUsedAt: nullTime(k.LastUsedAt),
+			}
+		}
+		return out.Render(rows)
+
+	case "revoke":
+		if len(cmd.Args) < 2 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("key ID or prefix is required")}
+		}
+		ref := cmd.Args[1]
+		n, err := s.DB.RevokeAPIKey(ctx, database.RevokeAPIKeyParams{UserID: user.ID, Ref: ref})
+		if err != nil {
+			return fmt.Errorf("failed to revoke API key: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("no API key %s found for %s", ref, user.Name)
+		}
+		fmt.Printf("Revoked API key %s\n", ref)
+		return nil
+
+	default:
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unknown apikey action %q", cmd.Args[0])}
+	}
+}
+
+func createAPIKey(ctx context.Context, q database.Querier, user database.User, name, scope string) (auth.Key, error) {
+	key, err := auth.NewKey()
+	if err != nil {
+		return key, err
+	}
+	now := time.Now()
+	_, err = q.CreateAPIKey(ctx, database.CreateAPIKeyParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    key.Prefix,
+		KeyHash:   key.Hash,
+		Scope:     scope,
+	})
+	if err != nil {
+		return key, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return key, nil
+}
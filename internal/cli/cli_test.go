@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"gator/internal/config"
+	"gator/internal/database"
+)
+
+// testCommands registers the commands the tests run the way main does.
+func testCommands() *Commands {
+	c := NewCommands()
+	c.Register("register", CommandInfo{MinArgs: 1, Flags: RegisterFlags}, HandlerRegister)
+	c.Register("login", CommandInfo{MinArgs: 1, Flags: LoginFlags}, HandlerLogin)
+	c.Register("passwd", CommandInfo{}, MiddlewareLoggedIn(HandlerPasswdLogged))
+	c.Register("addfeed", CommandInfo{MinArgs: 2}, MiddlewareLoggedIn(HandlerAddFeedLogged))
+	c.Register("follow", CommandInfo{MinArgs: 1}, MiddlewareLoggedIn(HandlerFollowLogged))
+	c.Register("following", CommandInfo{}, MiddlewareLoggedIn(HandlerFollowingLogged))
+	c.Register("unfollow", CommandInfo{MinArgs: 1}, MiddlewareLoggedIn(HandlerUnfollowLogged))
+	c.Register("browse", CommandInfo{Flags: BrowseFlags}, MiddlewareLoggedIn(HandlerBrowsePostsLogged))
+	c.Register("agg", CommandInfo{MinArgs: 1, Flags: AggFlags}, HandlerAgg)
+	c.Register("tag", CommandInfo{MinArgs: 2}, MiddlewareLoggedIn(HandlerTagLogged))
+	c.Register("reset", CommandInfo{Flags: ResetFlags}, HandlerReset)
+	c.Register("prune", CommandInfo{Flags: PruneFlags}, HandlerPrune)
+	c.Register("digest", CommandInfo{MinArgs: 1, Flags: DigestFlags}, HandlerDigest)
+	c.Register("webhook", CommandInfo{MinArgs: 1, Flags: WebhookFlags}, MiddlewareLoggedIn(HandlerWebhookLogged))
+	return c
+}
+
+// newTestState returns a State using db whose config file lives in a
+// temporary home directory.
+func newTestState(t *testing.T, db database.Store) *State {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GATOR_API_KEY", "")
+	return &State{
+		Ctx:    context.Background(),
+		Config: &config.Config{Log: config.LogConfig{Level: "error"}},
+		DB:     db,
+	}
+}
+
+// run runs the command line args against s with input as stdin and returns
+// what the command printed to stdout.
+func run(t *testing.T, s *State, input string, args ...string) (string, error) {
+	t.Helper()
+	oldStdin := stdin
+	stdin = bufio.NewReader(strings.NewReader(input))
+	defer func() { stdin = oldStdin }()
+
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	oldStdout := os.Stdout
+	os.Stdout = f
+	err = testCommands().Run(s, Command{Name: args[0], Args: args[1:]})
+	os.Stdout = oldStdout
+
+	if _, serr := f.Seek(0, io.SeekStart); serr != nil {
+		t.Fatal(serr)
+	}
+	out, rerr := io.ReadAll(f)
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	return string(out), err
+}
+
+// mustRun is run for commands that are expected to succeed.
+func mustRun(t *testing.T, s *State, input string, args ...string) string {
+	t.Helper()
+	out, err := run(t, s, input, args...)
+	if err != nil {
+		t.Fatalf("gator %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+const testPassword = "correct horse"
+
+// register creates a user with testPassword and logs in as them.
+func register(t *testing.T, s *State, name string) {
+	t.Helper()
+	mustRun(t, s, testPassword+"\n"+testPassword+"\n", "register", "--email", name+"@example.com", name)
+}
+
+// checkError fails t unless err contains want, or is nil when want is "".
+func checkError(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Fatalf("got no error, want one containing %q", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Fatalf("got error %q, want one containing %q", err, want)
+	}
+}
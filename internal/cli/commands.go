@@ -1,92 +1,276 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net/mail"
+	"os"
+	"os/signal"
+	"slices"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"gator/internal/aggregator"
+	"gator/internal/auth"
 	"gator/internal/config"
+	"gator/internal/cursor"
 	"gator/internal/database"
+	"gator/internal/events"
+	"gator/internal/logging"
+	"gator/internal/metrics"
+	"gator/internal/migrate"
+	"gator/internal/output"
 
 	"github.com/google/uuid"
-	"github.com/lib/pq"
 )
 
 type State struct {
-	Config *config.Config
-	DB     *database.Queries
+	// Ctx is the context the command runs in. main cancels it on SIGINT
+	// and SIGTERM, so handlers pass it to every query.
+	Ctx      context.Context
+	Config   *config.Config
+	DB       database.Store
+	Migrator *migrate.Migrator
+	// Events is told about every post scrapeFeeds stores. It is nil unless
+	// agg was asked to stream them.
+	Events *events.Bus
+	// Logger writes diagnostics to stderr. Run sets it from the config and
+	// the --log-level and --log-format flags before calling the handler.
+	Logger *slog.Logger
 }
 
 type Command struct {
 	Name string
 	Args []string
+	// Flags holds the command's parsed flags. Run fills it in from the
+	// CommandInfo the command was registered with.
+	Flags *flag.FlagSet
 }
 
 type HandlerFunc func(s *State, cmd Command) error
 
+// CommandInfo describes a command for argument parsing and help output.
+type CommandInfo struct {
+	// Usage lists the command's arguments, e.g. "[flags] <url>".
+	Usage string
+	// Description is a one-line summary shown by help.
+	Description string
+	// MinArgs is the number of positional arguments the command requires.
+	MinArgs int
+	// Flags defines the command's flags on fs, if it has any.
+	Flags func(fs *flag.FlagSet)
+}
+
+type command struct {
+	info    CommandInfo
+	handler HandlerFunc
+}
+
 type Commands struct {
-	handlers map[string]HandlerFunc
+	handlers map[string]command
 }
 
 func NewCommands() *Commands {
-	return &Commands{
-		handlers: make(map[string]HandlerFunc),
-	}
+	c := &Commands{
+		handlers: make(map[string]command),
+	}
+	c.Register("help", CommandInfo{
+		Usage:       "[command]",
+		Description: "List commands or show help for one command",
+	}, c.handlerHelp)
+	return c
+}
+
+func (c *Commands) Register(name string, info CommandInfo, f HandlerFunc) {
+	c.handlers[name] = command{info: info, handler: f}
+}
+
+// Has reports whether a command called name is registered.
+func (c *Commands) Has(name string) bool {
+	_, ok := c.handlers[name]
+	return ok
 }
 
-func (c *Commands) Register(name string, f HandlerFunc) {
-	c.handlers[name] = f
+// WantsHelp reports whether cmd only asks for its help text, which needs
+// no database.
+func (c *Commands) WantsHelp(cmd Command) bool {
+	registered, ok := c.handlers[cmd.Name]
+	if !ok {
+		return false
+	}
+	_, err := parseFlags(newFlagSet(cmd.Name, registered.info), cmd.Args)
+	return err == flag.ErrHelp
 }
 
 func (c *Commands) Run(s *State, cmd Command) error {
-	handler, exists := c.handlers[cmd.Name]
+	registered, exists := c.handlers[cmd.Name]
 	if !exists {
-		return fmt.Errorf("unknown comand: %s", cmd.Name)
+		err := fmt.Errorf("unknown command: %s", cmd.Name)
+		if suggestion := c.suggest(cmd.Name); suggestion != "" {
+			err = fmt.Errorf("%w (did you mean %s?)", err, suggestion)
+		}
+		return err
 	}
-	return handler(s, cmd)
+
+	fs := newFlagSet(cmd.Name, registered.info)
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			c.printHelp(os.Stdout, cmd.Name)
+			return nil
+		}
+		return &UsageError{Command: cmd.Name, Err: err}
+	}
+	if len(args) < registered.info.MinArgs {
+		return &UsageError{Command: cmd.Name, Err: errors.New("not enough arguments")}
+	}
+
+	logger, err := newLogger(s.Config, fs)
+	if err != nil {
+		return err
+	}
+	s.Logger = logger
+	slog.SetDefault(logger)
+
+	cmd.Args = args
+	cmd.Flags = fs
+	return registered.handler(s, cmd)
 }
 
-func HandlerRegister(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 {
-		return errors.New("username is required")
+// newLogger builds the diagnostic logger, preferring the --log-level and
+// --log-format flags to the config.
+func newLogger(cfg *config.Config, fs *flag.FlagSet) (*slog.Logger, error) {
+	level, format := cfg.Log.Level, cfg.Log.Format
+	if v := fs.Lookup("log-level").Value.String(); v != "" {
+		level = v
 	}
+	if v := fs.Lookup("log-format").Value.String(); v != "" {
+		format = v
+	}
+	return logging.New(os.Stderr, level, format)
+}
+
+// UsageError reports that a command was called with invalid arguments.
+type UsageError struct {
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.String("email", "", "the new user's email `address` (prompted for if not given)")
+}
+
+func HandlerRegister(s *State, cmd Command) error {
 	name := cmd.Args[0]
 
-	_, err := s.DB.GetUser(context.Background(), name)
+	_, err := s.DB.GetUser(s.Ctx, name)
 	if err == nil {
 		return fmt.Errorf("user %s already exists", name)
 	} else if err != sql.ErrNoRows {
 		return err
 	}
 
+	email := cmd.stringFlag("email")
+	if email == "" {
+		if email, err = promptLine("Email (optional): "); err != nil {
+			return err
+		}
+	}
+	if email != "" {
+		if email, err = checkEmail(s, email); err != nil {
+			return err
+		}
+	}
+	hash, err := newPassword()
+	if err != nil {
+		return err
+	}
+
+	ctx := s.Ctx
 	now := time.Now()
-	newUser, err := s.DB.CreateUser(context.Background(), database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Name:      name,
+	var (
+		newUser database.User
+		key     auth.Key
+	)
+	err = s.DB.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+		newUser, err = q.CreateUser(ctx, database.CreateUserParams{
+			ID:           uuid.New(),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Name:         name,
+			Email:        sql.NullString{String: email, Valid: email != ""},
+			PasswordHash: sql.NullString{String: hash, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		// Without a key the new user could not run any other command.
+		if s.Config.RequireAPIKey {
+			key, err = createAPIKey(ctx, q, newUser, "created by register", auth.ScopeFull)
+		}
+		return err
 	})
 	if err != nil {
 		return err
 	}
 
-	if err := s.Config.SetUser(name); err != nil {
+	if err := s.Config.SetLogin(name, key.Secret); err != nil {
 		return err
 	}
 
-	fmt.Printf("User %s create successfully!\nUserData: %+v\n", name, newUser)
+	fmt.Printf("User %s created successfully (ID %s)\n", newUser.Name, newUser.ID)
+	if key.Secret != "" {
+		fmt.Println("Created an API key and saved it to ~/.gatorconfig.json")
+	}
 	return nil
 }
 
+// checkEmail validates address and checks that no user has registered it,
+// returning it without any display name.
+func checkEmail(s *State, address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid email %q", address)
+	}
+	email := strings.ToLower(parsed.Address)
+	_, err = s.DB.GetUserByEmail(s.Ctx, sql.NullString{String: email, Valid: true})
+	if err == nil {
+		return "", fmt.Errorf("email %s is already in use", email)
+	}
+	if err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to check email: %w", err)
+	}
+	return email, nil
+}
+
 func MiddlewareLoggedIn(
 	handler func(s *State, cmd Command, user database.User) error,
 ) func(s *State, cmd Command) error {
 	return func(s *State, cmd Command) error {
-		user, err := s.DB.GetUser(context.Background(), s.Config.CurrentUserName)
+		if s.Config.RequireAPIKey {
+			user, err := authenticate(s)
+			if err != nil {
+				return err
+			}
+			return handler(s, cmd, user)
+		}
+		user, err := s.DB.GetUser(s.Ctx, s.Config.CurrentUserName)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve logged-in user: %w", err)
 		}
@@ -94,143 +278,352 @@ func MiddlewareLoggedIn(
 	}
 }
 
-func HandlerLogin(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 {
-		return errors.New("username is required")
+// authenticate returns the owner of the configured API key, ignoring
+// current_user_name, which anyone can edit.
+func authenticate(s *State) (database.User, error) {
+	key := os.Getenv("GATOR_API_KEY")
+	if key == "" {
+		key = s.Config.APIKey
+	}
+	if key == "" {
+		return database.User{}, errors.New("an API key is required: run gator login with --api-key or set GATOR_API_KEY")
+	}
+	user, err := auth.Authenticate(s.Ctx, s.DB, key, auth.ScopeFull)
+	if errors.Is(err, auth.ErrWrongScope) {
+		return user, errors.New("failed to authenticate: feed tokens can only read feeds")
+	}
+	if err != nil {
+		return user, fmt.Errorf("failed to authenticate: %w", err)
+	}
+	return user, nil
+}
+
+// requireKey checks the configured API key when API keys are required, for
+// commands that act on every user's data rather than the caller's own.
+func requireKey(s *State) error {
+	if !s.Config.RequireAPIKey {
+		return nil
 	}
-	username := cmd.Args[0]
+	_, err := authenticate(s)
+	return err
+}
+
+func LoginFlags(fs *flag.FlagSet) {
+	fs.String("api-key", "", "log in with this API `key` instead of a password, required when require_api_key is set")
+}
+
+const (
+	// maxLoginAttempts wrong passwords in a row lock an account for
+	// lockoutDuration.
+	maxLoginAttempts = 5
+	lockoutDuration  = 15 * time.Minute
+)
 
-	user, err := s.DB.GetUser(context.Background(), username)
+// HandlerLogin logs in by username or email. Users with a password must
+// enter it unless they log in with an API key.
+func HandlerLogin(s *State, cmd Command) error {
+	ctx := s.Ctx
+	login := cmd.Args[0]
+
+	var (
+		user database.User
+		err  error
+	)
+	if strings.Contains(login, "@") {
+		user, err = s.DB.GetUserByEmail(ctx, sql.NullString{String: strings.ToLower(login), Valid: true})
+	} else {
+		user, err = s.DB.GetUser(ctx, login)
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("user %s does not exist", username)
+			return fmt.Errorf("user %s does not exist", login)
 		}
 		return err
 	}
 
-	if err := s.Config.SetUser(username); err != nil {
+	key := cmd.stringFlag("api-key")
+	if key == "" && s.Config.RequireAPIKey {
+		return errors.New("an API key is required to log in: pass --api-key")
+	}
+	switch {
+	case key != "":
+		owner, err := auth.Authenticate(ctx, s.DB, key, auth.ScopeFull)
+		if errors.Is(err, auth.ErrWrongScope) {
+			return errors.New("failed to authenticate: feed tokens can only read feeds")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+		if owner.ID != user.ID {
+			return fmt.Errorf("API key does not belong to %s", user.Name)
+		}
+	case user.PasswordHash.Valid:
+		if err := checkPassword(ctx, s, user, "Password: "); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Config.SetLogin(user.Name, key); err != nil {
 		return err
 	}
 
-	fmt.Printf("Logged in as %s. User data: %+v\n", username, user)
+	fmt.Printf("Logged in as %s\n", user.Name)
+	if !user.PasswordHash.Valid {
+		fmt.Println("You have no password yet: set one with gator passwd")
+	}
 	return nil
 }
 
-func HandlerReset(s *State, cmd Command) error {
-	err := s.DB.ResetUsers(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to reset database: %w", err)
+// checkPassword prompts for user's password and checks it. Wrong answers
+// are counted, and after maxLoginAttempts of them the account is locked
+// for lockoutDuration. A correct answer clears the count.
+func checkPassword(ctx context.Context, s *State, user database.User, prompt string) error {
+	if user.LockedUntil.Valid && time.Now().Before(user.LockedUntil.Time) {
+		return fmt.Errorf("too many failed attempts: %s is locked until %s",
+			user.Name, user.LockedUntil.Time.Local().Format(time.Kitchen))
 	}
-	fmt.Println("Database reset successfully!")
-	return nil
-}
 
-func HandlerUsers(s *State, cmd Command) error {
-	users, err := s.DB.GetUsers(context.Background())
+	password, err := promptPassword(prompt)
 	if err != nil {
-		return fmt.Errorf("failed to get users: %w", err)
+		return err
 	}
 
-	current := s.Config.CurrentUserName
+	if auth.CheckPassword(user.PasswordHash.String, password) {
+		if user.FailedLogins == 0 && !user.LockedUntil.Valid {
+			return nil
+		}
+		err := s.DB.SetLoginFailures(ctx, database.SetLoginFailuresParams{ID: user.ID})
+		if err != nil {
+			return fmt.Errorf("failed to reset failed logins: %w", err)
+		}
+		return nil
+	}
 
-	for _, u := range users {
-		if u.Name == current {
-			fmt.Printf("* %s (current)\n", u.Name)
-		} else {
-			fmt.Printf("* %s\n", u.Name)
+	// Count the failure in the database rather than from user, which may be
+	// stale if another login is failing at the same time.
+	failed, err := s.DB.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record failed login: %w", err)
+	}
+	if failed < maxLoginAttempts {
+		return errors.New("incorrect password")
+	}
+	err = s.DB.SetLoginFailures(ctx, database.SetLoginFailuresParams{
+		ID:          user.ID,
+		LockedUntil: sql.NullTime{Time: time.Now().UTC().Add(lockoutDuration), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+	return fmt.Errorf("incorrect password: too many failed attempts, %s is locked for %s", user.Name, lockoutDuration)
+}
+
+// HandlerPasswdLogged sets the logged-in user's password, asking for the
+// current one first if there is one.
+func HandlerPasswdLogged(s *State, cmd Command, user database.User) error {
+	ctx := s.Ctx
+	if user.PasswordHash.Valid {
+		if err := checkPassword(ctx, s, user, "Current password: "); err != nil {
+			return err
 		}
 	}
+
+	hash, err := newPassword()
+	if err != nil {
+		return err
+	}
+	err = s.DB.SetUserPassword(ctx, database.SetUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+
+	fmt.Printf("Password changed for %s\n", user.Name)
 	return nil
 }
 
+func AggFlags(fs *flag.FlagSet) {
+	fs.String("listen", "", "also stream new posts as Server-Sent Events on this `address`")
+	fs.String("metrics", "", "also serve Prometheus metrics at /metrics on this `address`")
+	fs.Duration("due-after", time.Hour, "count a feed as due once this long has passed since its last fetch")
+}
+
 func HandlerAgg(s *State, cmd Command) error {
-	if len(cmd.Args) < 1 {
-		return errors.New("time_between_reqs is required (e.g., 1m)")
-	}
 	durationStr := cmd.Args[0]
 	timeBetweenReqs, err := time.ParseDuration(durationStr)
 	if err != nil {
 		return fmt.Errorf("invalid duration: %w", err)
 	}
+	if addr := cmd.stringFlag("listen"); addr != "" {
+		if err := streamEvents(s, addr); err != nil {
+			return err
+		}
+	}
+	if addr := cmd.stringFlag("metrics"); addr != "" {
+		if err := serveMetrics(s, addr); err != nil {
+			return err
+		}
+	}
+	dueAfter := cmd.durationFlag("due-after")
+
+	// s.Ctx ends on SIGINT or SIGTERM, after which no new work is started.
+	// work outlives it by shutdownTimeout so that a fetch or insert already
+	// under way can finish; anything still running then is cancelled, which
+	// rolls back its transaction.
+	ctx := s.Ctx
+	work, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	context.AfterFunc(ctx, func() {
+		time.AfterFunc(shutdownTimeout, cancelWork)
+	})
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenReqs)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	s.Logger.Info("collecting feeds", "interval", timeBetweenReqs)
 	ticker := time.NewTicker(timeBetweenReqs)
 	defer ticker.Stop()
 
 	for {
-		if err := scrapeFeeds(s); err != nil {
-			fmt.Printf("Error scraping feeds: %v\n", err)
+		if ctx.Err() == nil {
+			if err := scrapeFeeds(work, s, dueAfter); err != nil {
+				s.Logger.Error("failed to scrape feeds", "error", err)
+			}
+		}
+		if ctx.Err() == nil {
+			if err := deliverWebhooks(work, s); err != nil {
+				s.Logger.Error("failed to deliver webhooks", "error", err)
+			}
+		}
+		if ctx.Err() == nil && s.Config.Retention.PruneOnAgg {
+			n, err := prunePosts(work, s, false, false)
+			if err != nil {
+				s.Logger.Error("failed to prune posts", "error", err)
+			} else if n > 0 {
+				s.Logger.Info("pruned posts", "posts", n)
+			}
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				s.Logger.Info("shutting down")
+				return nil
+			case <-hup:
+				reloadConfig(s, cmd)
+			case <-ticker.C:
+				break wait
+			}
 		}
-		<-ticker.C
 	}
 }
 
-func HandlerAddFeedLogged(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 2 {
-		return errors.New("feed name and URL are required")
+// reloadConfig rereads the config file into s.Config on SIGHUP. Settings
+// read on every round, such as retention, SMTP and logging, take effect at
+// once; the database connection is kept.
+func reloadConfig(s *State, cmd Command) {
+	cfg, err := config.Read()
+	if err != nil {
+		s.Logger.Error("failed to reload config", "error", err)
+		return
 	}
+	logger, err := newLogger(&cfg, cmd.Flags)
+	if err != nil {
+		s.Logger.Error("failed to reload config", "error", err)
+		return
+	}
+	if cfg.DBURL != s.Config.DBURL {
+		s.Logger.Warn("db_url changed; restart to connect to the new database")
+	}
+	*s.Config = cfg
+	s.Logger = logger
+	slog.SetDefault(logger)
+	s.Logger.Info("reloaded config")
+}
 
+func HandlerAddFeedLogged(s *State, cmd Command, user database.User) error {
 	feedName := cmd.Args[0]
 	feedURL := cmd.Args[1]
 
+	ctx := s.Ctx
 	now := time.Now()
-	newFeed, err := s.DB.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Name:      feedName,
-		Url:       feedURL,
-		UserID:    user.ID,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create feed: %w", err)
-	}
+	var newFeed database.Feed
+	err := s.DB.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+		newFeed, err = q.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      feedName,
+			Url:       feedURL,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create feed: %w", err)
+		}
 
-	_, err = s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		UserID:    user.ID,
-		FeedID:    newFeed.ID,
+		_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			UserID:    user.ID,
+			FeedID:    newFeed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to automatically follow feed: %w", err)
+		}
+		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to automatically follow feed: %w", err)
+		return err
 	}
 
-	fmt.Printf("Feed created successfully: %+v\n", newFeed)
+	fmt.Printf("Feed %s created successfully (%s)\n", newFeed.Name, newFeed.Url)
 	return nil
 }
 
+type feedRow struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	CreatedBy string `json:"created_by"`
+}
+
 func HandlerFeeds(s *State, cmd Command) error {
-	feeds, err := s.DB.GetAllFeeds(context.Background())
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+
+	feeds, err := s.DB.GetAllFeeds(s.Ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get feeds: %w", err)
 	}
 
 	if len(feeds) == 0 {
-		fmt.Println("No feeds found.")
-		return nil
+		out.Notef("No feeds found.")
 	}
 
-	for _, feed := range feeds {
-		fmt.Printf("Feed: %s\nURL: %s\nCreated by: %s\n\n", feed.FeedName, feed.Url, feed.UserName)
+	rows := make([]feedRow, len(feeds))
+	for i, feed := range feeds {
+		rows[i] = feedRow{Name: feed.FeedName, URL: feed.Url, CreatedBy: feed.UserName}
 	}
-	return nil
+	return out.Render(rows)
 }
 
 func HandlerFollowLogged(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 1 {
-		return errors.New("feed URL is required")
-	}
 	feedURL := cmd.Args[0]
 
-	feed, err := s.DB.GetFeedByURL(context.Background(), feedURL)
+	feed, err := s.DB.GetFeedByURL(s.Ctx, feedURL)
 	if err != nil {
 		return fmt.Errorf("failed to find feed with URL %s: %w", feedURL, err)
 	}
 
 	now := time.Now()
-	follow, err := s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	follow, err := s.DB.CreateFeedFollow(s.Ctx, database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -245,31 +638,86 @@ func HandlerFollowLogged(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+type followRow struct {
+	Feed   string   `json:"feed"`
+	URL    string   `json:"url"`
+	Tags   []string `json:"tags"`
+	Unread int64    `json:"unread"`
+}
+
 func HandlerFollowingLogged(s *State, cmd Command, user database.User) error {
-	follows, err := s.DB.GetFeedFollowsForUser(context.Background(), user.ID)
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+
+	follows, err := s.DB.GetFeedFollowsForUser(s.Ctx, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get feed follows: %w", err)
 	}
 
 	if len(follows) == 0 {
-		fmt.Println("No feed follows found.")
-		return nil
+		out.Notef("No feed follows found.")
 	}
 
-	fmt.Println("Following:")
-	for _, ff := range follows {
-		fmt.Printf("- %s\n", ff.FeedName)
+	tags, err := s.DB.GetFeedFollowTagsForUser(s.Ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
 	}
-	return nil
+
+	tagsByFollow := make(map[uuid.UUID][]string)
+	for _, t := range tags {
+		tagsByFollow[t.FeedFollowID] = append(tagsByFollow[t.FeedFollowID], t.TagName)
+	}
+
+	rows := make([]followRow, len(follows))
+	for i, ff := range follows {
+		rows[i] = followRow{
+			Feed:   ff.FeedName,
+			URL:    ff.FeedUrl,
+			Tags:   tagsByFollow[ff.ID],
+			Unread: ff.UnreadCount,
+		}
+	}
+	if cmd.stringFlag("output") == output.Table {
+		return out.Render(groupByTag(rows))
+	}
+	return out.Render(rows)
 }
 
-func HandlerUnfollowLogged(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 1 {
-		return errors.New("feed URL is required")
+type followTagRow struct {
+	Tag    string `json:"tag"`
+	Feed   string `json:"feed"`
+	URL    string `json:"url"`
+	Unread int64  `json:"unread"`
+}
+
+// groupByTag lists each follow once per tag, sorted by tag and feed name,
+// with the untagged follows last. Only tables are grouped this way.
+func groupByTag(rows []followRow) []followTagRow {
+	var tagged, untagged []followTagRow
+	for _, r := range rows {
+		if len(r.Tags) == 0 {
+			untagged = append(untagged, followTagRow{Tag: "[untagged]", Feed: r.Feed, URL: r.URL, Unread: r.Unread})
+			continue
+		}
+		for _, tag := range r.Tags {
+			tagged = append(tagged, followTagRow{Tag: tag, Feed: r.Feed, URL: r.URL, Unread: r.Unread})
+		}
 	}
+	slices.SortStableFunc(tagged, func(a, b followTagRow) int {
+		if c := strings.Compare(a.Tag, b.Tag); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Feed, b.Feed)
+	})
+	return append(tagged, untagged...)
+}
+
+func HandlerUnfollowLogged(s *State, cmd Command, user database.User) error {
 	feedURL := cmd.Args[0]
 
-	err := s.DB.DeleteFeedFollow(context.Background(), database.DeleteFeedFollowParams{
+	err := s.DB.DeleteFeedFollow(s.Ctx, database.DeleteFeedFollowParams{
 		UserID: user.ID,
 		Url:    feedURL,
 	})
@@ -280,13 +728,13 @@ func HandlerUnfollowLogged(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
-func scrapeFeeds(s *State) error {
-	ctx := context.Background()
-
+// scrapeFeeds fetches the feed that has waited longest and stores its new
+// posts. Feeds not fetched within dueAfter are reported as due.
+func scrapeFeeds(ctx context.Context, s *State, dueAfter time.Duration) error {
 	feed, err := s.DB.GetNextFeedToFetch(ctx)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			fmt.Println("No feed to fetch.")
+			s.Logger.Info("no feed to fetch")
 			return nil
 		}
 		return fmt.Errorf("failed to get next feed: %w", err)
@@ -295,73 +743,220 @@ func scrapeFeeds(s *State) error {
 	if err := s.DB.MarkedFeedFetched(ctx, feed.ID); err != nil {
 		return fmt.Errorf("failed to mark feed fetched: %w", err)
 	}
+	due, err := s.DB.CountDueFeeds(ctx, sql.NullTime{Time: time.Now().Add(-dueAfter), Valid: true})
+	if err != nil {
+		// The gauge is only informational, so don't let it stop the fetch.
+		s.Logger.Error("failed to count due feeds", "error", err)
+	} else {
+		metrics.FeedsDue.Set(float64(due))
+	}
 
+	logger := s.Logger.With("feed_id", feed.ID, "url", feed.Url)
+	start := time.Now()
 	rssFeed, err := aggregator.FetchFeed(ctx, feed.Url)
 	if err != nil {
-		return fmt.Errorf("failed to fetch feed: %w", err)
+		// The feed's server is at fault, not agg, so carry on with the
+		// next feed.
+		logger.Warn("failed to fetch feed", "duration", time.Since(start), "error", err)
+		return nil
 	}
-
-	now := time.Now()
-	for _, item := range rssFeed.Channel.Item {
-		var publishedAt time.Time
-		if item.PubDate != "" {
-			publishedAt, err = time.Parse(time.RFC1123, item.PubDate)
-			if err != nil {
-				publishedAt, err = time.Parse(time.RFC1123Z, item.PubDate)
+	fetched := time.Since(start)
+
+	// PostgreSQL stores microseconds, and lib/pq writes the local wall clock
+	// time into timestamp columns. Truncating in UTC keeps the times
+	// published to s.Events identical to the stored ones, which the event
+	// stream's resume IDs depend on.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	var created []database.Post
+	// Insert the whole batch or nothing; posts already stored are skipped by
+	// ON CONFLICT (url) DO NOTHING rather than failing the transaction.
+	err = s.DB.ExecTx(ctx, func(q database.Querier) error {
+		created = created[:0]
+		for _, item := range rssFeed.Channel.Item {
+			var publishedAt time.Time
+			if item.PubDate != "" {
+				publishedAt, err = time.Parse(time.RFC1123, item.PubDate)
 				if err != nil {
-					publishedAt = time.Time{}
+					publishedAt, err = time.Parse(time.RFC1123Z, item.PubDate)
+					if err != nil {
+						logger.Debug("ignoring unparseable pubDate", "post_url", item.Link, "pub_date", item.PubDate)
+						publishedAt = time.Time{}
+					}
 				}
 			}
-		}
 
-		err = s.DB.CreatePost(ctx, database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   now,
-			UpdatedAt:   now,
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
-			PublishedAt: sql.NullTime{Time: publishedAt, Valid: !publishedAt.IsZero()},
-			FeedID:      feed.ID,
-		})
-		if err != nil {
-			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			author := item.Author
+			if author == "" {
+				author = item.Creator
+			}
 
-			} else {
-				fmt.Printf("Failed to create post for '%s': %v\n", item.Title, err)
+			post := database.Post{
+				ID:          uuid.New(),
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Title:       item.Title,
+				Url:         item.Link,
+				Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+				PublishedAt: sql.NullTime{Time: publishedAt, Valid: !publishedAt.IsZero()},
+				FeedID:      feed.ID,
+				Author:      sql.NullString{String: author, Valid: author != ""},
+			}
+			n, err := q.CreatePost(ctx, database.CreatePostParams(post))
+			if err != nil {
+				return fmt.Errorf("failed to create post '%s': %w", item.Title, err)
+			}
+			if n > 0 {
+				created = append(created, post)
 			}
 		}
+		return enqueueWebhooks(ctx, q, feed, created)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save posts for %s: %w", feed.Name, err)
+	}
+	metrics.PostsInserted.Add(float64(len(created)))
+	metrics.PostsDuplicate.Add(float64(len(rssFeed.Channel.Item) - len(created)))
+	logger.Info("fetched feed",
+		"duration", fetched,
+		"posts", len(rssFeed.Channel.Item),
+		"new_posts", len(created),
+	)
+
+	// Publish in (created_at, id) order, the order the event stream resumes
+	// in. Every post in the batch has the same created_at.
+	slices.SortFunc(created, func(a, b database.Post) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	for _, post := range created {
+		s.Events.Publish(events.PostCreated{Post: post, Feed: feed})
 	}
 	return nil
 }
 
+func BrowseFlags(fs *flag.FlagSet) {
+	fs.Bool("unread", false, "only show unread posts (default)")
+	fs.Bool("all", false, "include posts that have already been read")
+	fs.String("tag", "", "only show posts from feeds with this `tag`")
+	fs.String("feed", "", "only show posts from the feed with this `URL`")
+	fs.String("author", "", "only show posts whose author contains this `text`")
+	fs.String("since", "", "only show posts published on or after this `date`")
+	fs.String("until", "", "only show posts published before this `date`")
+	fs.String("sort", "published", "sort `order`: published, fetched or feed")
+	fs.Int("limit", 2, "maximum number of posts to show")
+	fs.Int("offset", 0, "number of posts to skip")
+	fs.String("after", "", "`cursor` printed by a previous browse to continue from")
+}
+
+type postRow struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Feed        string     `json:"feed"`
+	URL         string     `json:"url"`
+	Author      string     `json:"author"`
+	PublishedAt *time.Time `json:"published_at"`
+	Read        bool       `json:"read"`
+}
+
 func HandlerBrowsePostsLogged(s *State, cmd Command, user database.User) error {
-	limit := 2
-	if len(cmd.Args) >= 1 {
-		if parsedLimit, err := strconv.Atoi(cmd.Args[0]); err == nil {
-			limit = parsedLimit
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+
+	all := cmd.boolFlag("all")
+	if cmd.boolFlag("unread") && all {
+		return errors.New("--unread and --all cannot be used together")
+	}
+
+	limit, offset := cmd.intFlag("limit"), cmd.intFlag("offset")
+	if len(cmd.Args) > 1 {
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unexpected arguments: %v", cmd.Args[1:])}
+	}
+	if len(cmd.Args) == 1 {
+		parsedLimit, err := strconv.Atoi(cmd.Args[0])
+		if err != nil {
+			return fmt.Errorf("invalid limit %q: must be a number", cmd.Args[0])
 		}
+		limit = parsedLimit
+	}
+	if limit < 1 {
+		return errors.New("limit must be at least 1")
+	}
+	if offset < 0 {
+		return errors.New("--offset cannot be negative")
 	}
 
-	posts, err := s.DB.GetPostsForUSer(context.Background(), database.GetPostsForUSerParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-	})
+	sortBy := cmd.stringFlag("sort")
+	switch sortBy {
+	case "published", "fetched", "feed":
+	default:
+		return fmt.Errorf("invalid sort %q: must be published, fetched or feed", sortBy)
+	}
+
+	tag, feedURL, author := cmd.stringFlag("tag"), cmd.stringFlag("feed"), cmd.stringFlag("author")
+	params := database.BrowsePostsForUserParams{
+		UserID:     user.ID,
+		UnreadOnly: !all,
+		Tag:        sql.NullString{String: tag, Valid: tag != ""},
+		FeedUrl:    sql.NullString{String: feedURL, Valid: feedURL != ""},
+		Author:     sql.NullString{String: author, Valid: author != ""},
+		Sort:       sortBy,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+	if since := cmd.stringFlag("since"); since != "" {
+		t, err := parseDate(since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if until := cmd.stringFlag("until"); until != "" {
+		t, err := parseDate(until)
+		if err != nil {
+			return err
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+	if after := cmd.stringFlag("after"); after != "" {
+		if offset != 0 {
+			return errors.New("--after and --offset cannot be used together")
+		}
+		c, err := cursor.Decode(after)
+		if err != nil {
+			return errors.New("invalid --after cursor")
+		}
+		c.Apply(&params)
+	}
+
+	posts, err := s.DB.BrowsePostsForUser(s.Ctx, params)
 	if err != nil {
 		return fmt.Errorf("failed to get posts for user: %w", err)
 	}
 
 	if len(posts) == 0 {
-		fmt.Println("No posts found.")
-		return nil
+		out.Notef("No posts found.")
+	}
+
+	rows := make([]postRow, len(posts))
+	for i, post := range posts {
+		rows[i] = postRow{
+			ID:          post.ID,
+			Title:       post.Title,
+			Feed:        post.FeedName,
+			URL:         post.Url,
+			Author:      post.Author.String,
+			PublishedAt: nullTime(post.PublishedAt),
+			Read:        post.ReadAt.Valid,
+		}
+	}
+	if err := out.Render(rows); err != nil {
+		return err
 	}
 
-	for _, post := range posts {
-		publishedAt := "N/A"
-		if post.PublishedAt.Valid {
-			publishedAt = post.PublishedAt.Time.Format(time.RFC3339)
-		}
-		fmt.Printf("Title: %s\nURL: %s\n Published: %s\n\n", post.Title, post.Url, publishedAt)
+	if len(posts) == limit {
+		next := cursor.After(posts[len(posts)-1], sortBy)
+		out.Notef("Next page: --after %s", next.Encode())
 	}
 	return nil
 }
@@ -0,0 +1,399 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+	"gator/internal/database/memory"
+	"gator/internal/events"
+
+	"github.com/google/uuid"
+)
+
+func TestRegister(t *testing.T) {
+	twice := testPassword + "\n" + testPassword + "\n"
+	tests := []struct {
+		name      string
+		args      []string
+		input     string
+		wantErr   string
+		wantEmail string
+	}{
+		{"email flag", []string{"--email", "Alice@Example.com", "alice"}, twice, "", "alice@example.com"},
+		{"prompted email", []string{"alice"}, "alice@example.com\n" + twice, "", "alice@example.com"},
+		{"no email", []string{"alice"}, "\n" + twice, "", ""},
+		{"taken name", []string{"bob"}, "\n" + twice, "user bob already exists", ""},
+		{"taken email", []string{"--email", "bob@example.com", "alice"}, twice, "already in use", ""},
+		{"invalid email", []string{"--email", "nope", "alice"}, twice, "invalid email", ""},
+		{"short password", []string{"--email", "a@example.com", "alice"}, "short\nshort\n", "at least 8 characters", ""},
+		{"mismatched passwords", []string{"--email", "a@example.com", "alice"}, testPassword + "\nsomething else\n", "do not match", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Each(t, func(t *testing.T, db database.Store) {
+				s := newTestState(t, db)
+				register(t, s, "bob")
+
+				_, err := run(t, s, tt.input, append([]string{"register"}, tt.args...)...)
+				checkError(t, err, tt.wantErr)
+				if tt.wantErr != "" {
+					if s.Config.CurrentUserName != "bob" {
+						t.Errorf("logged in as %q after failing, want bob", s.Config.CurrentUserName)
+					}
+					return
+				}
+
+				if s.Config.CurrentUserName != "alice" {
+					t.Errorf("logged in as %q, want alice", s.Config.CurrentUserName)
+				}
+				user, err := db.GetUser(context.Background(), "alice")
+				if err != nil {
+					t.Fatalf("GetUser: %v", err)
+				}
+				if user.Email.String != tt.wantEmail || user.Email.Valid != (tt.wantEmail != "") {
+					t.Errorf("email = %+v, want %q", user.Email, tt.wantEmail)
+				}
+				if !user.PasswordHash.Valid || user.PasswordHash.String == testPassword {
+					t.Errorf("password not hashed: %+v", user.PasswordHash)
+				}
+			})
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		login    string
+		attempts []string
+		wantErr  string
+		wantUser string
+	}{
+		{"by name", "alice", []string{testPassword}, "", "alice"},
+		{"by email", "ALICE@example.com", []string{testPassword}, "", "alice"},
+		{"wrong password", "alice", []string{"wrong password"}, "incorrect password", "bob"},
+		{"unknown user", "carol", []string{testPassword}, "user carol does not exist", "bob"},
+		{"failures reset on success", "alice", []string{"wrong", "wrong", "wrong", "wrong", testPassword}, "", "alice"},
+		{"lockout", "alice", []string{"wrong", "wrong", "wrong", "wrong", "wrong"}, "too many failed attempts", "bob"},
+		{"locked out", "alice", []string{"wrong", "wrong", "wrong", "wrong", "wrong", testPassword}, "is locked until", "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Each(t, func(t *testing.T, db database.Store) {
+				s := newTestState(t, db)
+				register(t, s, "alice")
+				register(t, s, "bob")
+
+				var err error
+				for _, password := range tt.attempts {
+					_, err = run(t, s, password+"\n", "login", tt.login)
+				}
+				checkError(t, err, tt.wantErr)
+				if s.Config.CurrentUserName != tt.wantUser {
+					t.Errorf("logged in as %q, want %q", s.Config.CurrentUserName, tt.wantUser)
+				}
+			})
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	const url = "https://blog.example.com/rss"
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, s *State)
+		args    []string
+		wantErr string
+		want    []string
+	}{
+		{"follow", nil, []string{"follow", url}, "", []string{"Blog", "Own"}},
+		{"already following", func(t *testing.T, s *State) {
+			mustRun(t, s, "", "follow", url)
+		}, []string{"follow", url}, "failed to follow feed", []string{"Blog", "Own"}},
+		{"unknown feed", nil, []string{"follow", "https://nowhere.example.com/rss"}, "failed to find feed", []string{"Own"}},
+		{"unfollow", func(t *testing.T, s *State) {
+			mustRun(t, s, "", "follow", url)
+		}, []string{"unfollow", url}, "", []string{"Own"}},
+		{"unfollow own feed", nil, []string{"unfollow", "https://own.example.com/rss"}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Each(t, func(t *testing.T, db database.Store) {
+				s := newTestState(t, db)
+				register(t, s, "bob")
+				mustRun(t, s, "", "addfeed", "Blog", url)
+				register(t, s, "alice")
+				mustRun(t, s, "", "addfeed", "Own", "https://own.example.com/rss")
+				if tt.setup != nil {
+					tt.setup(t, s)
+				}
+
+				_, err := run(t, s, "", tt.args...)
+				checkError(t, err, tt.wantErr)
+
+				out := mustRun(t, s, "", "following", "--output", "json")
+				var rows []followRow
+				if err := json.Unmarshal([]byte(out), &rows); err != nil {
+					t.Fatalf("invalid JSON %q: %v", out, err)
+				}
+				var got []string
+				for _, r := range rows {
+					got = append(got, r.Feed)
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("following %v, want %v", got, tt.want)
+				}
+			})
+		})
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+		want    []string
+	}{
+		{"default limit", nil, "", []string{"p4", "p3"}},
+		{"limit argument", []string{"3"}, "", []string{"p4", "p3", "p2"}},
+		{"unread only", []string{"--limit", "10"}, "", []string{"p4", "p3", "p2"}},
+		{"all", []string{"--all", "--limit", "10"}, "", []string{"p4", "p3", "p2", "p1"}},
+		{"feed", []string{"--all", "--feed", "https://b.example.com/rss"}, "", []string{"p4", "p2"}},
+		{"offset", []string{"--all", "--offset", "3"}, "", []string{"p1"}},
+		{"since", []string{"--all", "--since", "2025-01-03"}, "", []string{"p4", "p3"}},
+		{"unread and all", []string{"--unread", "--all"}, "cannot be used together", nil},
+		{"bad limit", []string{"0"}, "at least 1", nil},
+		{"bad sort", []string{"--sort", "random"}, "invalid sort", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Each(t, func(t *testing.T, db database.Store) {
+				s := newTestState(t, db)
+				ctx := context.Background()
+				register(t, s, "alice")
+				mustRun(t, s, "", "addfeed", "A", "https://a.example.com/rss")
+				mustRun(t, s, "", "addfeed", "B", "https://b.example.com/rss")
+				feedA, _ := db.GetFeedByURL(ctx, "https://a.example.com/rss")
+				feedB, _ := db.GetFeedByURL(ctx, "https://b.example.com/rss")
+				user, _ := db.GetUser(ctx, "alice")
+
+				for i, feed := range []database.Feed{feedA, feedB, feedA, feedB} {
+					published := time.Date(2025, 1, i+1, 0, 0, 0, 0, time.UTC)
+					post := database.CreatePostParams{
+						ID:          uuid.New(),
+						CreatedAt:   published,
+						UpdatedAt:   published,
+						Title:       fmt.Sprintf("p%d", i+1),
+						Url:         fmt.Sprintf("https://example.com/%d", i+1),
+						PublishedAt: sql.NullTime{Time: published, Valid: true},
+						FeedID:      feed.ID,
+					}
+					if _, err := db.CreatePost(ctx, post); err != nil {
+						t.Fatalf("CreatePost: %v", err)
+					}
+					if i == 0 {
+						err := db.MarkPostRead(ctx, database.MarkPostReadParams{UserID: user.ID, PostID: post.ID})
+						if err != nil {
+							t.Fatalf("MarkPostRead: %v", err)
+						}
+					}
+				}
+
+				out, err := run(t, s, "", append([]string{"browse", "--output", "json"}, tt.args...)...)
+				checkError(t, err, tt.wantErr)
+				if err != nil {
+					return
+				}
+				var rows []postRow
+				if err := json.Unmarshal([]byte(out), &rows); err != nil {
+					t.Fatalf("invalid JSON %q: %v", out, err)
+				}
+				var got []string
+				for _, r := range rows {
+					got = append(got, r.Title)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("browse %v = %v, want %v", tt.args, got, tt.want)
+				}
+			})
+		})
+	}
+}
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel>
+<title>Test</title><link>https://example.com</link><description>Test feed</description>
+<item><title>First</title><link>https://example.com/1</link><pubDate>Mon, 06 Jan 2025 10:00:00 +0000</pubDate><author>Ann</author></item>
+<item><title>Second</title><link>https://example.com/2</link><pubDate>Tue, 07 Jan 2025 10:00:00 GMT</pubDate></item>
+<item><title>Undated</title><link>https://example.com/3</link></item>
+</channel></rss>`
+
+func TestAgg(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantPosts []string
+	}{
+		{"stores posts", http.StatusOK, testRSS, []string{"Undated", "Second", "First"}},
+		{"server error", http.StatusInternalServerError, "", nil},
+		{"invalid feed", http.StatusOK, "<html>not a feed", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbtest.Each(t, func(t *testing.T, db database.Store) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				// agg runs until its context ends, so end it once the feed
+				// has been served. The fetch under way still finishes.
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					defer cancel()
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.body)
+				}))
+				defer srv.Close()
+
+				s := newTestState(t, db)
+				register(t, s, "alice")
+				mustRun(t, s, "", "addfeed", "Test", srv.URL)
+
+				s.Ctx = ctx
+				done := make(chan error, 1)
+				go func() {
+					_, err := run(t, s, "", "agg", "1h")
+					done <- err
+				}()
+				select {
+				case err := <-done:
+					checkError(t, err, "")
+				case <-time.After(10 * time.Second):
+					t.Fatal("agg did not stop")
+				}
+
+				feed, err := db.GetFeedByURL(context.Background(), srv.URL)
+				if err != nil {
+					t.Fatalf("GetFeedByURL: %v", err)
+				}
+				if !feed.LastFetchedAt.Valid {
+					t.Error("feed not marked fetched")
+				}
+				s.Ctx = context.Background()
+				out := mustRun(t, s, "", "browse", "--all", "--limit", "10", "--output", "{{.Title}}")
+				got := strings.Fields(out)
+				if !slices.Equal(got, tt.wantPosts) {
+					t.Errorf("stored posts %v, want %v", got, tt.wantPosts)
+				}
+			})
+		})
+	}
+}
+
+func TestFollowingGroupsByTag(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		s := newTestState(t, db)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Go Blog", "https://go.example.com/rss")
+		mustRun(t, s, "", "addfeed", "Rust Blog", "https://rust.example.com/rss")
+		mustRun(t, s, "", "addfeed", "News", "https://news.example.com/rss")
+		mustRun(t, s, "", "tag", "https://go.example.com/rss", "programming")
+		mustRun(t, s, "", "tag", "https://rust.example.com/rss", "programming")
+		mustRun(t, s, "", "tag", "https://go.example.com/rss", "golang")
+
+		out := mustRun(t, s, "", "following")
+		var got [][]string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			fields := strings.Fields(line)
+			got = append(got, fields[:len(fields)-2])
+		}
+		want := [][]string{
+			{"golang", "Go", "Blog"},
+			{"programming", "Go", "Blog"},
+			{"programming", "Rust", "Blog"},
+			{"[untagged]", "News"},
+		}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("following table:\n%s\nwant rows %v", out, want)
+		}
+	})
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		key     string
+		wantErr string
+	}{
+		{"prune without key", []string{"prune", "--dry-run"}, "", "an API key is required"},
+		{"prune with bad key", []string{"prune", "--dry-run"}, "gator_nope", "failed to authenticate"},
+		{"prune with key", []string{"prune", "--dry-run"}, "valid", ""},
+		{"digest send without key", []string{"digest", "send", "--dry-run"}, "", "an API key is required"},
+		{"digest send with key", []string{"digest", "send", "--dry-run"}, "valid", ""},
+		{"digest run without key", []string{"digest", "run"}, "", "an API key is required"},
+		{"reset without key", []string{"reset", "--yes", "--no-snapshot"}, "", "reset is disabled"},
+		{"reset with key", []string{"reset", "--yes", "--no-snapshot"}, "valid", "reset is disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestState(t, memory.New())
+			s.Config.RequireAPIKey = true
+			register(t, s, "alice")
+			if tt.key != "valid" {
+				s.Config.APIKey = tt.key
+			}
+
+			_, err := run(t, s, "", tt.args...)
+			checkError(t, err, tt.wantErr)
+			if _, err := s.DB.GetUser(context.Background(), "alice"); err != nil {
+				t.Errorf("alice is gone: %v", err)
+			}
+		})
+	}
+}
+
+func TestScrapeFeedsPublishesStoredTimes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSS)
+	}))
+	defer srv.Close()
+
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		s := newTestState(t, db)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Test", srv.URL)
+		s.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		s.Events = events.NewBus()
+		sub := s.Events.Subscribe()
+		defer sub.Close()
+
+		if err := scrapeFeeds(ctx, s, time.Hour); err != nil {
+			t.Fatalf("scrapeFeeds: %v", err)
+		}
+		for range 3 {
+			e := <-sub.C
+			stored, err := db.GetPostByURL(ctx, e.Post.Url)
+			if err != nil {
+				t.Fatalf("GetPostByURL: %v", err)
+			}
+			// Event stream cursors compare these, so they must match exactly.
+			if e.Post.CreatedAt.Location() != time.UTC || !e.Post.CreatedAt.Equal(stored.CreatedAt) {
+				t.Errorf("published created_at %v, stored %v", e.Post.CreatedAt, stored.CreatedAt)
+			}
+		}
+	})
+}
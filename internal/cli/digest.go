@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/digest"
+
+	"github.com/google/uuid"
+)
+
+// digestLimit is the most posts a single digest lists.
+const digestLimit = 200
+
+func DigestFlags(fs *flag.FlagSet) {
+	fs.String("email", "", "daily, weekly: send digests to this `address`, saving it as your email")
+	fs.Bool("html", false, "preview: show the HTML version instead of plain text")
+	fs.Bool("dry-run", false, "send, run: show who would get a digest without sending or recording anything")
+	fs.Duration("interval", time.Hour, "run: how often to check for due digests")
+}
+
+type digestRow struct {
+	User      string     `json:"user"`
+	Email     string     `json:"email"`
+	Frequency string     `json:"frequency"`
+	LastSent  *time.Time `json:"last_sent"`
+	Posts     int        `json:"posts"`
+	Status    string     `json:"status"`
+}
+
+func HandlerDigest(s *State, cmd Command) error {
+	switch cmd.Args[0] {
+	case digest.Daily, digest.Weekly, "off", "status", "preview":
+		return MiddlewareLoggedIn(handlerDigestLogged)(s, cmd)
+
+	case "send":
+		if err := requireKey(s); err != nil {
+			return err
+		}
+		return sendDigests(s.Ctx, s, cmd)
+
+	case "run":
+		if err := requireKey(s); err != nil {
+			return err
+		}
+		interval := cmd.durationFlag("interval")
+		if interval <= 0 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("--interval must be positive")}
+		}
+		s.Logger.Info("sending due digests", "interval", interval)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := sendDigests(s.Ctx, s, cmd); err != nil {
+				s.Logger.Error("failed to send digests", "error", err)
+			}
+			select {
+			case <-s.Ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+
+	default:
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unknown digest action %q", cmd.Args[0])}
+	}
+}
+
+func handlerDigestLogged(s *State, cmd Command, user database.User) error {
+	ctx := s.Ctx
+
+	switch cmd.Args[0] {
+	case digest.Daily, digest.Weekly:
+		if address := cmd.stringFlag("email"); address != "" {
+			email, err := checkEmail(s, address)
+			if err != nil && !strings.EqualFold(address, user.Email.String) {
+				return err
+			}
+			if err == nil {
+				user.Email = sql.NullString{String: email, Valid: true}
+				if err := s.DB.SetUserEmail(ctx, database.SetUserEmailParams{ID: user.ID, Email: user.Email}); err != nil {
+					return fmt.Errorf("failed to set email: %w", err)
+				}
+			}
+		}
+		if !user.Email.Valid {
+			return errors.New("you have no email address: pass one with --email")
+		}
+		now := time.Now()
+		_, err := s.DB.SetDigestFrequency(ctx, database.SetDigestFrequencyParams{
+			UserID:    user.ID,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Frequency: cmd.Args[0],
+		})
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to digests: %w", err)
+		}
+		fmt.Printf("Sending a %s digest of unread posts to %s\n", cmd.Args[0], user.Email.String)
+		return nil
+
+	case "off":
+		n, err := s.DB.DeleteDigestSubscription(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to unsubscribe from digests: %w", err)
+		}
+		if n == 0 {
+			fmt.Println("You are not subscribed to digests")
+			return nil
+		}
+		fmt.Println("Digests turned off")
+		return nil
+
+	case "status":
+		out, err := cmd.renderer()
+		if err != nil {
+			return err
+		}
+		sub, err := s.DB.GetDigestSubscription(ctx, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			out.Notef("You are not subscribed to digests.")
+			return out.Render([]digestRow{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get digest subscription: %w", err)
+		}
+		d, err := buildDigest(ctx, s, user.Name, user.ID, sub.Frequency, sub.LastSentAt, time.Now())
+		if err != nil {
+			return err
+		}
+		row := digestRow{
+			User:      user.Name,
+			Email:     user.Email.String,
+			Frequency: sub.Frequency,
+			Posts:     d.PostCount(),
+			Status:    "scheduled",
+		}
+		if sub.LastSentAt.Valid {
+			row.LastSent = &sub.LastSentAt.Time
+		}
+		return out.Render([]digestRow{row})
+
+	case "preview":
+		sub, err := s.DB.GetDigestSubscription(ctx, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			sub = database.DigestSubscription{Frequency: digest.Daily}
+		} else if err != nil {
+			return fmt.Errorf("failed to get digest subscription: %w", err)
+		}
+		d, err := buildDigest(ctx, s, user.Name, user.ID, sub.Frequency, sub.LastSentAt, time.Now())
+		if err != nil {
+			return err
+		}
+		msg, err := digest.Render(d)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Subject: %s\n\n", msg.Subject)
+		if cmd.boolFlag("html") {
+			fmt.Print(msg.HTML)
+		} else {
+			fmt.Print(msg.Text)
+		}
+		return nil
+	}
+	return nil
+}
+
+// buildDigest collects the unread posts a user's next digest covers: those
+// stored since the last digest, or within one period for the first one.
+// If there are more than digestLimit, the digest ends at the fetch time of
+// the last post that fits, and the rest are left for the next digest.
+func buildDigest(ctx context.Context, s *State, name string, userID uuid.UUID, frequency string, lastSent sql.NullTime, now time.Time) (digest.Digest, error) {
+	since := now.Add(-digest.Period(frequency))
+	if lastSent.Valid {
+		since = lastSent.Time
+	}
+	posts, err := s.DB.GetDigestPosts(ctx, database.GetDigestPostsParams{
+		UserID: userID,
+		Since:  since,
+		Until:  now,
+		Limit:  digestLimit + 1,
+	})
+	if err != nil {
+		return digest.Digest{}, fmt.Errorf("failed to get posts for %s's digest: %w", name, err)
+	}
+
+	until := now
+	if len(posts) > digestLimit {
+		// Posts fetched at the same time can't be split between digests,
+		// which only record a time, so end before them.
+		until = posts[digestLimit-1].CreatedAt
+		n := digestLimit
+		for n > 0 && posts[n-1].CreatedAt.Equal(until) {
+			n--
+		}
+		if n > 0 {
+			posts, until = posts[:n], posts[n-1].CreatedAt
+		} else {
+			// More than digestLimit posts were fetched at once, so send
+			// them all.
+			posts, err = s.DB.GetDigestPosts(ctx, database.GetDigestPostsParams{
+				UserID: userID,
+				Since:  since,
+				Until:  until,
+				Limit:  math.MaxInt32,
+			})
+			if err != nil {
+				return digest.Digest{}, fmt.Errorf("failed to get posts for %s's digest: %w", name, err)
+			}
+		}
+	}
+	return digest.New(name, frequency, since, until, posts), nil
+}
+
+// sendDigests emails every subscriber whose digest is due and records when
+// it was sent. A digest with no unread posts is skipped but still counts as
+// sent, so the next one does not repeat the period.
+func sendDigests(ctx context.Context, s *State, cmd Command) error {
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+	dryRun := cmd.boolFlag("dry-run")
+	subs, err := s.DB.GetDigestSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get digest subscriptions: %w", err)
+	}
+
+	var rows []digestRow
+	var failed int
+	for _, sub := range subs {
+		now := time.Now()
+		if sub.LastSentAt.Valid && now.Sub(sub.LastSentAt.Time) < digest.Period(sub.Frequency) {
+			continue
+		}
+		row := digestRow{User: sub.UserName, Email: sub.Email.String, Frequency: sub.Frequency}
+		if sub.LastSentAt.Valid {
+			row.LastSent = &sub.LastSentAt.Time
+		}
+		if !sub.Email.Valid {
+			row.Status = "skipped: no email"
+			rows = append(rows, row)
+			continue
+		}
+
+		d, err := buildDigest(ctx, s, sub.UserName, sub.UserID, sub.Frequency, sub.LastSentAt, now)
+		if err != nil {
+			return err
+		}
+		row.Posts = d.PostCount()
+		switch {
+		case dryRun:
+			row.Status = "due"
+		case row.Posts == 0:
+			row.Status = "skipped: nothing new"
+			if err := recordDigest(ctx, s, sub.UserID, sql.NullTime{Time: d.Until, Valid: true}); err != nil {
+				row.Status = "failed: " + err.Error()
+				failed++
+			}
+		default:
+			row.Status = "sent"
+			if err := sendDigest(ctx, s, sub, d); err != nil {
+				row.Status = "failed: " + err.Error()
+				failed++
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		out.Notef("No digests are due.")
+		return nil
+	}
+	if err := out.Render(rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d digests failed to send", failed, len(rows))
+	}
+	return nil
+}
+
+// sendDigest emails d to the subscriber. The digest is recorded as sent
+// first, so that failing to record it can't lead to sending it twice, and
+// the record is undone if the email can't be sent.
+func sendDigest(ctx context.Context, s *State, sub database.GetDigestSubscriptionsRow, d digest.Digest) error {
+	msg, err := digest.Render(d)
+	if err != nil {
+		return err
+	}
+	if err := recordDigest(ctx, s, sub.UserID, sql.NullTime{Time: d.Until, Valid: true}); err != nil {
+		return err
+	}
+	sendErr := digest.Send(ctx, s.Config.SMTP, sub.Email.String, msg)
+	if sendErr == nil {
+		return nil
+	}
+	if err := recordDigest(ctx, s, sub.UserID, sub.LastSentAt); err != nil {
+		// The posts stay unread but won't be in a digest.
+		return fmt.Errorf("%w, and then %w", sendErr, err)
+	}
+	return sendErr
+}
+
+func recordDigest(ctx context.Context, s *State, userID uuid.UUID, sent sql.NullTime) error {
+	err := s.DB.SetDigestSent(ctx, database.SetDigestSentParams{UserID: userID, LastSentAt: sent})
+	if err != nil {
+		return fmt.Errorf("failed to record digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,394 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gator/internal/config"
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+
+	"github.com/google/uuid"
+)
+
+// smtpServer is a plaintext SMTP server that accepts any message, or
+// rejects every recipient while reject is set.
+type smtpServer struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	reject   bool
+	from     string
+	to       []string
+	messages []string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &smtpServer{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+// config returns the settings for sending through srv without TLS.
+func (srv *smtpServer) config() config.SMTPConfig {
+	addr := srv.ln.Addr().(*net.TCPAddr)
+	return config.SMTPConfig{
+		Host:     addr.IP.String(),
+		Port:     addr.Port,
+		From:     "gator@example.com",
+		Security: config.SMTPNone,
+	}
+}
+
+func (srv *smtpServer) setReject(reject bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.reject = reject
+}
+
+func (srv *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			srv.mu.Lock()
+			srv.from = arg
+			srv.mu.Unlock()
+			reply("250 ok")
+		case "RCPT":
+			srv.mu.Lock()
+			reject := srv.reject
+			if !reject {
+				srv.to = append(srv.to, arg)
+			}
+			srv.mu.Unlock()
+			if reject {
+				reply("550 no such user")
+			} else {
+				reply("250 ok")
+			}
+		case "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				msg.WriteString(strings.TrimPrefix(line, "."))
+			}
+			srv.mu.Lock()
+			srv.messages = append(srv.messages, msg.String())
+			srv.mu.Unlock()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// parts reads the parts of a multipart/alternative message by content type,
+// with their transfer encoding undone.
+func parts(t *testing.T, m *mail.Message) map[string]string {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", m.Header.Get("Content-Type"))
+	}
+	got := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		body, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("reading %s part: %v", p.Header.Get("Content-Type"), err)
+		}
+		got[p.Header.Get("Content-Type")] = string(body)
+	}
+}
+
+func TestDigestSend(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSS)
+	}))
+	defer feed.Close()
+
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		srv := newSMTPServer(t)
+		s := newTestState(t, db)
+		s.Config.SMTP = srv.config()
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Test", feed.URL)
+		if err := scrapeFeeds(ctx, s, time.Hour); err != nil {
+			t.Fatalf("scrapeFeeds: %v", err)
+		}
+		mustRun(t, s, "", "digest", "daily")
+		user, err := db.GetUser(ctx, "alice")
+		if err != nil {
+			t.Fatalf("GetUser: %v", err)
+		}
+		subscription := func() database.DigestSubscription {
+			t.Helper()
+			sub, err := db.GetDigestSubscription(ctx, user.ID)
+			if err != nil {
+				t.Fatalf("GetDigestSubscription: %v", err)
+			}
+			return sub
+		}
+
+		// A failed send leaves the digest due.
+		srv.setReject(true)
+		_, err = run(t, s, "", "digest", "send")
+		checkError(t, err, "1 of 1 digests failed to send")
+		if sub := subscription(); sub.LastSentAt.Valid {
+			t.Errorf("last_sent_at = %v after a failed send, want it unset", sub.LastSentAt.Time)
+		}
+
+		srv.setReject(false)
+		start := time.Now()
+		mustRun(t, s, "", "digest", "send")
+		if sub := subscription(); !sub.LastSentAt.Valid || sub.LastSentAt.Time.Before(start.Add(-time.Second)) {
+			t.Errorf("last_sent_at = %+v after sending, want about %v", sub.LastSentAt, start)
+		}
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if len(srv.messages) != 1 {
+			t.Fatalf("server got %d messages, want 1", len(srv.messages))
+		}
+		if srv.from != "FROM:<gator@example.com>" || len(srv.to) != 1 || srv.to[0] != "TO:<alice@example.com>" {
+			t.Errorf("envelope = %s %v, want gator@example.com to alice@example.com", srv.from, srv.to)
+		}
+		m, err := mail.ReadMessage(strings.NewReader(srv.messages[0]))
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if got := m.Header.Get("Subject"); got != "gator digest: 3 unread posts from 1 feed" {
+			t.Errorf("Subject = %q", got)
+		}
+		if got := m.Header.Get("To"); got != "alice@example.com" {
+			t.Errorf("To = %q", got)
+		}
+		bodies := parts(t, m)
+		if len(bodies) != 2 {
+			t.Errorf("got parts %v, want plain text and HTML", bodies)
+		}
+		text := bodies["text/plain; charset=utf-8"]
+		for _, want := range []string{"Hi alice,", "== Test ==", "* First", "https://example.com/1", "by Ann"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("text part does not contain %q:\n%s", want, text)
+			}
+		}
+		html := bodies["text/html; charset=utf-8"]
+		for _, want := range []string{"<p>Hi alice,</p>", `<a href="https://example.com/1">First</a>`} {
+			if !strings.Contains(html, want) {
+				t.Errorf("HTML part does not contain %q:\n%s", want, html)
+			}
+		}
+	})
+}
+
+func TestDigestSendPastLimit(t *testing.T) {
+	const total = digestLimit + 50
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		srv := newSMTPServer(t)
+		s := newTestState(t, db)
+		s.Config.SMTP = srv.config()
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Blog", "https://blog.example.com/rss")
+		mustRun(t, s, "", "digest", "daily")
+		user, err := db.GetUser(ctx, "alice")
+		if err != nil {
+			t.Fatalf("GetUser: %v", err)
+		}
+		feed, err := db.GetFeedByURL(ctx, "https://blog.example.com/rss")
+		if err != nil {
+			t.Fatalf("GetFeedByURL: %v", err)
+		}
+
+		// The last digest went out three days ago. Since then a post was
+		// fetched every second, except for a batch fetched together that
+		// straddles the limit.
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		err = db.SetDigestSent(ctx, database.SetDigestSentParams{
+			UserID:     user.ID,
+			LastSentAt: sql.NullTime{Time: now.Add(-72 * time.Hour), Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("SetDigestSent: %v", err)
+		}
+		first := now.Add(-60 * time.Hour)
+		batch := digestLimit - 5
+		for i := range total {
+			fetched := first.Add(time.Duration(min(i, batch)) * time.Second)
+			if i > batch+10 {
+				fetched = first.Add(time.Duration(i) * time.Second)
+			}
+			_, err := db.CreatePost(ctx, database.CreatePostParams{
+				ID:        uuid.New(),
+				CreatedAt: fetched,
+				UpdatedAt: fetched,
+				Title:     fmt.Sprintf("Post %03d", i),
+				Url:       fmt.Sprintf("https://blog.example.com/%03d/", i),
+				FeedID:    feed.ID,
+			})
+			if err != nil {
+				t.Fatalf("CreatePost: %v", err)
+			}
+		}
+
+		// The first digest stops before the batch and records the time of
+		// its last post, so the rest are due at once.
+		mustRun(t, s, "", "digest", "send")
+		sub, err := db.GetDigestSubscription(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetDigestSubscription: %v", err)
+		}
+		if want := first.Add(time.Duration(batch-1) * time.Second); !sub.LastSentAt.Time.Equal(want) {
+			t.Errorf("last_sent_at = %v, want %v", sub.LastSentAt.Time, want)
+		}
+		mustRun(t, s, "", "digest", "send")
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if len(srv.messages) != 2 {
+			t.Fatalf("server got %d messages, want 2", len(srv.messages))
+		}
+		sent := map[string]int{}
+		postURL := regexp.MustCompile(`https://blog\.example\.com/\d{3}/`)
+		for i, want := range []string{
+			fmt.Sprintf("gator digest: %d unread posts from 1 feed", batch),
+			fmt.Sprintf("gator digest: %d unread posts from 1 feed", total-batch),
+		} {
+			m, err := mail.ReadMessage(strings.NewReader(srv.messages[i]))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			if got := m.Header.Get("Subject"); got != want {
+				t.Errorf("digest %d: Subject = %q, want %q", i+1, got, want)
+			}
+			for _, url := range postURL.FindAllString(parts(t, m)["text/plain; charset=utf-8"], -1) {
+				sent[url]++
+			}
+		}
+		for i := range total {
+			url := fmt.Sprintf("https://blog.example.com/%03d/", i)
+			if sent[url] != 1 {
+				t.Errorf("%s was in %d digests, want 1", url, sent[url])
+			}
+		}
+	})
+}
+
+func TestDigestSendRecordFailure(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		srv := newSMTPServer(t)
+		store := &failingStore{Store: db}
+		s := newTestState(t, store)
+		s.Config.SMTP = srv.config()
+		register(t, s, "alice")
+		mustRun(t, s, "", "digest", "daily")
+		register(t, s, "bob")
+		mustRun(t, s, "", "digest", "daily")
+		mustRun(t, s, "", "addfeed", "Blog", "https://blog.example.com/rss")
+		mustRun(t, s, testPassword+"\n", "login", "alice")
+		mustRun(t, s, "", "follow", "https://blog.example.com/rss")
+
+		feed, err := db.GetFeedByURL(ctx, "https://blog.example.com/rss")
+		if err != nil {
+			t.Fatalf("GetFeedByURL: %v", err)
+		}
+		fetched := time.Now().Add(-time.Hour)
+		_, err = db.CreatePost(ctx, database.CreatePostParams{
+			ID: uuid.New(), CreatedAt: fetched, UpdatedAt: fetched, Title: "News", Url: "https://blog.example.com/news", FeedID: feed.ID,
+		})
+		if err != nil {
+			t.Fatalf("CreatePost: %v", err)
+		}
+		alice, err := db.GetUser(ctx, "alice")
+		if err != nil {
+			t.Fatalf("GetUser: %v", err)
+		}
+
+		// Alice's digest can't be recorded, so it isn't sent, but bob's
+		// still is.
+		store.failDigestFor = alice.ID
+		out, err := run(t, s, "", "digest", "send", "--output", "json")
+		checkError(t, err, "1 of 2 digests failed to send")
+		var rows []digestRow
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("digest send: %v\n%s", err, out)
+		}
+		status := map[string]string{}
+		for _, row := range rows {
+			status[row.User] = row.Status
+		}
+		if !strings.Contains(status["alice"], "failed to record digest") || status["bob"] != "sent" {
+			t.Errorf("statuses = %v, want alice's failed to record and bob's sent", status)
+		}
+
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		if len(srv.to) != 1 || srv.to[0] != "TO:<bob@example.com>" {
+			t.Errorf("sent to %v, want only bob", srv.to)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"gator/internal/database"
+)
+
+func RmFeedFlags(fs *flag.FlagSet) {
+	fs.Bool("force", false, "delete the feed even if other users follow it")
+	fs.Bool("transfer", false, "hand the feed to its longest-standing other follower instead of deleting it")
+}
+
+func HandlerRmFeedLogged(s *State, cmd Command, user database.User) error {
+	force, transfer := cmd.boolFlag("force"), cmd.boolFlag("transfer")
+	if force && transfer {
+		return errors.New("--force and --transfer cannot be used together")
+	}
+	feedURL := cmd.Args[0]
+
+	ctx := s.Ctx
+	feed, err := getOwnedFeed(ctx, s, user, feedURL, "remove")
+	if err != nil {
+		return err
+	}
+
+	others, err := otherFollowers(ctx, s, feed, user)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(others) > 0 && transfer:
+		newOwner := others[0]
+		err = s.DB.ExecTx(ctx, func(q database.Querier) error {
+			err := q.SetFeedOwner(ctx, database.SetFeedOwnerParams{
+				ID:     feed.ID,
+				UserID: newOwner.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to transfer feed: %w", err)
+			}
+			err = q.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+				UserID: user.ID,
+				Url:    feed.Url,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to unfollow feed: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Transferred feed %s to %s and unfollowed it\n", feed.Name, newOwner.Name)
+		return nil
+
+	case len(others) > 0 && !force:
+		return fmt.Errorf("feed %s is followed by %d other user(s); use --transfer to hand it to %s or --force to delete it for everyone",
+			feed.Name, len(others), others[0].Name)
+	}
+
+	// Follows, posts and read state cascade with the feed. Starred copies
+	// survive with their post reference cleared.
+	if err := s.DB.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+	if len(others) > 0 {
+		fmt.Printf("Deleted feed %s and unfollowed it for %d other user(s)\n", feed.Name, len(others))
+	} else {
+		fmt.Printf("Deleted feed %s\n", feed.Name)
+	}
+	return nil
+}
+
+func HandlerRenameFeedLogged(s *State, cmd Command, user database.User) error {
+	feedURL := cmd.Args[0]
+	name := strings.TrimSpace(strings.Join(cmd.Args[1:], " "))
+	if name == "" {
+		return errors.New("feed name cannot be empty")
+	}
+
+	ctx := s.Ctx
+	feed, err := getOwnedFeed(ctx, s, user, feedURL, "rename")
+	if err != nil {
+		return err
+	}
+
+	renamed, err := s.DB.RenameFeed(ctx, database.RenameFeedParams{
+		ID:   feed.ID,
+		Name: name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename feed: %w", err)
+	}
+
+	fmt.Printf("Renamed feed %s to %s\n", feed.Name, renamed.Name)
+	return nil
+}
+
+func SetFeedURLFlags(fs *flag.FlagSet) {
+	fs.Bool("clear-posts", false, "delete the posts fetched from the old URL")
+}
+
+func HandlerSetFeedURLLogged(s *State, cmd Command, user database.User) error {
+	clearPosts := cmd.boolFlag("clear-posts")
+	oldURL, newURL := cmd.Args[0], cmd.Args[1]
+
+	ctx := s.Ctx
+	feed, err := getOwnedFeed(ctx, s, user, oldURL, "edit")
+	if err != nil {
+		return err
+	}
+
+	var cleared int64
+	err = s.DB.ExecTx(ctx, func(q database.Querier) error {
+		var err error
+		feed, err = q.UpdateFeedURL(ctx, database.UpdateFeedURLParams{
+			ID:  feed.ID,
+			Url: newURL,
+		})
+		if err != nil {
+			if database.IsUniqueViolation(err) {
+				return fmt.Errorf("a feed with URL %s already exists", newURL)
+			}
+			return fmt.Errorf("failed to update feed URL: %w", err)
+		}
+		if clearPosts {
+			cleared, err = q.DeletePostsForFeed(ctx, feed.ID)
+			if err != nil {
+				return fmt.Errorf("failed to delete posts: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Feed %s now points to %s\n", feed.Name, feed.Url)
+	if clearPosts {
+		fmt.Printf("Deleted %d posts from the old URL\n", cleared)
+	}
+	return nil
+}
+
+// getOwnedFeed looks up the feed with feedURL and checks that user added it.
+// action names the operation in the error shown to anyone else.
+func getOwnedFeed(ctx context.Context, s *State, user database.User, feedURL, action string) (database.Feed, error) {
+	feed, err := s.DB.GetFeedByURL(ctx, feedURL)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return feed, fmt.Errorf("no feed found with URL %s", feedURL)
+		}
+		return feed, fmt.Errorf("failed to get feed: %w", err)
+	}
+	if feed.UserID != user.ID {
+		return feed, fmt.Errorf("only the user who added feed %s can %s it", feed.Name, action)
+	}
+	return feed, nil
+}
+
+// otherFollowers returns the users other than user who follow feed, in the
+// order they followed it.
+func otherFollowers(ctx context.Context, s *State, feed database.Feed, user database.User) ([]database.User, error) {
+	followers, err := s.DB.GetFeedFollowers(ctx, feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed followers: %w", err)
+	}
+	var others []database.User
+	for _, f := range followers {
+		if f.ID != user.ID {
+			others = append(others, f)
+		}
+	}
+	return others, nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gator/internal/logging"
+	"gator/internal/output"
+)
+
+func (c *Commands) handlerHelp(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		c.PrintHelp(os.Stdout, "")
+		return nil
+	}
+	name := cmd.Args[0]
+	if !c.Has(name) {
+		err := fmt.Errorf("unknown command: %s", name)
+		if suggestion := c.suggest(name); suggestion != "" {
+			err = fmt.Errorf("%w (did you mean %s?)", err, suggestion)
+		}
+		return err
+	}
+	c.PrintHelp(os.Stdout, name)
+	return nil
+}
+
+// PrintHelp writes the usage, description and flags of the named command to
+// w, or a summary of every command if name is empty.
+func (c *Commands) PrintHelp(w io.Writer, name string) {
+	if name == "" {
+		c.printCommandList(w)
+		return
+	}
+	c.printHelp(w, name)
+}
+
+func (c *Commands) printCommandList(w io.Writer) {
+	names := make([]string, 0, len(c.handlers))
+	for name := range c.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fmt.Fprintln(w, "Usage: gator <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, c.handlers[name].info.Description)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'gator help <command>' for details about a command.")
+}
+
+func (c *Commands) printHelp(w io.Writer, name string) {
+	registered := c.handlers[name]
+	fmt.Fprintf(w, "Usage: gator %s %s\n", name, registered.info.Usage)
+	if registered.info.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", registered.info.Description)
+	}
+
+	fs := newFlagSet(name, registered.info)
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+// suggest returns the registered command closest to name, or "" if none is
+// close enough to be a likely typo.
+func (c *Commands) suggest(name string) string {
+	best, bestDist := "", 3
+	for candidate := range c.handlers {
+		dist := editDistance(name, candidate)
+		if len(name) >= 2 && strings.HasPrefix(candidate, name) {
+			dist = min(dist, 1)
+		}
+		if dist < bestDist || (dist == bestDist && candidate < best) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func newFlagSet(name string, info CommandInfo) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if info.Flags != nil {
+		info.Flags(fs)
+	}
+	fs.String("output", output.Table, "output `format`: "+strings.Join(output.Formats, ", ")+" or a Go template")
+	fs.String("log-level", "", "diagnostic log `level`: debug, info, warn or error (default from config, else info)")
+	fs.String("log-format", "", "diagnostic log `format`: "+strings.Join(logging.Formats, " or ")+" (default from config, else text)")
+	return fs
+}
+
+// parseFlags parses args with fs, allowing flags to appear before, between
+// or after positional arguments, and returns the positional arguments.
+// Everything after "--" is positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		consumed := len(args) - fs.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, fs.Args()...), nil
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (cmd Command) flagValue(name string) any {
+	f := cmd.Flags.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("flag -%s is not defined for command %s", name, cmd.Name))
+	}
+	return f.Value.(flag.Getter).Get()
+}
+
+func (cmd Command) boolFlag(name string) bool {
+	return cmd.flagValue(name).(bool)
+}
+
+func (cmd Command) stringFlag(name string) string {
+	return cmd.flagValue(name).(string)
+}
+
+func (cmd Command) intFlag(name string) int {
+	return cmd.flagValue(name).(int)
+}
+
+func (cmd Command) durationFlag(name string) time.Duration {
+	return cmd.flagValue(name).(time.Duration)
+}
+
+// renderer returns the renderer for the format chosen with --output.
+func (cmd Command) renderer() (*output.Renderer, error) {
+	return output.New(os.Stdout, os.Stderr, cmd.stringFlag("output"))
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type migrationRow struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+func HandlerMigrate(s *State, cmd Command) error {
+	ctx := s.Ctx
+
+	switch cmd.Args[0] {
+	case "up":
+		applied, err := s.Migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database schema is up to date.")
+		}
+		return nil
+
+	case "down":
+		m, err := s.Migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %s\n", m.Name)
+		return nil
+
+	case "to":
+		if len(cmd.Args) < 2 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("target version is required")}
+		}
+		version, err := strconv.ParseInt(cmd.Args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", cmd.Args[1])
+		}
+		changed, err := s.Migrator.To(ctx, version)
+		for _, m := range changed {
+			fmt.Printf("Migrated %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database schema is at version %d\n", version)
+		return nil
+
+	case "status":
+		out, err := cmd.renderer()
+		if err != nil {
+			return err
+		}
+		statuses, err := s.Migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		rows := make([]migrationRow, len(statuses))
+		for i, st := range statuses {
+			rows[i] = migrationRow{
+				Version:   st.Version,
+				Name:      st.Name,
+				Applied:   st.AppliedAt.Valid,
+				AppliedAt: nullTime(st.AppliedAt),
+			}
+		}
+		return out.Render(rows)
+
+	default:
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unknown migrate subcommand: %s", cmd.Args[0])}
+	}
+}
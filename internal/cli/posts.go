@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func HandlerReadLogged(s *State, cmd Command, user database.User) error {
+	post, err := getPostByRef(s.Ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.MarkPostRead(s.Ctx, database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark post read: %w", err)
+	}
+
+	fmt.Printf("Marked as read: %s\n", post.Title)
+	return nil
+}
+
+func HandlerUnreadLogged(s *State, cmd Command, user database.User) error {
+	post, err := getPostByRef(s.Ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.MarkPostUnread(s.Ctx, database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark post unread: %w", err)
+	}
+
+	fmt.Printf("Marked as unread: %s\n", post.Title)
+	return nil
+}
+
+func MarkAllFlags(fs *flag.FlagSet) {
+	fs.String("feed", "", "only mark posts from the feed with this `URL`")
+	fs.String("before", "", "only mark posts published before this `date` (YYYY-MM-DD or RFC3339)")
+}
+
+func HandlerMarkAllLogged(s *State, cmd Command, user database.User) error {
+	if cmd.Args[0] != "read" {
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unknown markall action %q", cmd.Args[0])}
+	}
+
+	feedURL := cmd.stringFlag("feed")
+	params := database.MarkAllPostsReadParams{
+		UserID:  user.ID,
+		FeedUrl: sql.NullString{String: feedURL, Valid: feedURL != ""},
+	}
+	if before := cmd.stringFlag("before"); before != "" {
+		t, err := parseDate(before)
+		if err != nil {
+			return err
+		}
+		params.Before = sql.NullTime{Time: t, Valid: true}
+	}
+
+	n, err := s.DB.MarkAllPostsRead(s.Ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to mark posts read: %w", err)
+	}
+
+	fmt.Printf("Marked %d posts as read\n", n)
+	return nil
+}
+
+func StarFlags(fs *flag.FlagSet) {
+	fs.String("note", "", "a note to keep with the saved post")
+}
+
+func HandlerStarLogged(s *State, cmd Command, user database.User) error {
+	post, err := getPostByRef(s.Ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	starred, err := s.DB.StarPost(s.Ctx, database.StarPostParams{
+		ID:     uuid.New(),
+		UserID: user.ID,
+		Note:   cmd.stringFlag("note"),
+		PostID: post.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to star post: %w", err)
+	}
+
+	fmt.Printf("Starred: %s\n", starred.Title)
+	return nil
+}
+
+func HandlerUnstarLogged(s *State, cmd Command, user database.User) error {
+	ref := cmd.Args[0]
+
+	n, err := s.DB.UnstarPost(s.Ctx, database.UnstarPostParams{
+		UserID: user.ID,
+		Ref:    ref,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unstar post: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("post %s is not starred", ref)
+	}
+
+	fmt.Printf("Unstarred %s\n", ref)
+	return nil
+}
+
+func SavedFlags(fs *flag.FlagSet) {
+	fs.Int("limit", 10, "number of posts per page")
+	fs.Int("page", 1, "page number to show")
+}
+
+type savedPostRow struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Feed        string     `json:"feed"`
+	URL         string     `json:"url"`
+	PublishedAt *time.Time `json:"published_at"`
+	StarredAt   time.Time  `json:"starred_at"`
+	Note        string     `json:"note"`
+}
+
+func HandlerSavedLogged(s *State, cmd Command, user database.User) error {
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+
+	limit, page := cmd.intFlag("limit"), cmd.intFlag("page")
+	if limit < 1 {
+		return errors.New("--limit must be at least 1")
+	}
+	if page < 1 {
+		return errors.New("--page must be at least 1")
+	}
+
+	total, err := s.DB.CountStarredPostsForUser(s.Ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count saved posts: %w", err)
+	}
+
+	posts, err := s.DB.GetStarredPostsForUser(s.Ctx, database.GetStarredPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(limit),
+		Offset: int32((page - 1) * limit),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get saved posts: %w", err)
+	}
+
+	if len(posts) == 0 {
+		out.Notef("No saved posts found.")
+		return out.Render([]savedPostRow{})
+	}
+
+	rows := make([]savedPostRow, len(posts))
+	for i, post := range posts {
+		rows[i] = savedPostRow{
+			ID:          post.ID,
+			Title:       post.Title,
+			Feed:        post.FeedName,
+			URL:         post.Url,
+			PublishedAt: nullTime(post.PublishedAt),
+			StarredAt:   post.CreatedAt,
+			Note:        post.Note,
+		}
+	}
+	if err := out.Render(rows); err != nil {
+		return err
+	}
+
+	pages := (int(total) + limit - 1) / limit
+	out.Notef("Page %d of %d (%d saved posts)", page, pages, total)
+	return nil
+}
+
+// getPostByRef looks a post up by its ID, falling back to its URL.
+func getPostByRef(ctx context.Context, s *State, ref string) (database.Post, error) {
+	var (
+		post database.Post
+		err  error
+	)
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		post, err = s.DB.GetPostByID(ctx, id)
+	} else {
+		post, err = s.DB.GetPostByURL(ctx, ref)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return post, fmt.Errorf("post %s not found", ref)
+		}
+		return post, fmt.Errorf("failed to get post: %w", err)
+	}
+	return post, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC3339", value)
+}
+
+// nullTime returns a pointer to t's time, or nil if t is NULL, so output rows
+// render missing timestamps as null or empty.
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gator/internal/auth"
+
+	"golang.org/x/term"
+)
+
+// stdin is shared by every prompt so that input buffered while reading one
+// answer is not lost to the next, which matters when answers are piped in.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stdin and reports whether the answer
+// was yes. Anything other than y or yes, including EOF, counts as no.
+func confirm(prompt string) (bool, error) {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// promptLine prints prompt and returns the next line of stdin without
+// surrounding whitespace.
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		if err == io.EOF {
+			return "", errors.New("no input")
+		}
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// promptPassword prints prompt and reads a password without echoing it when
+// stdin is a terminal. Otherwise the password is read as a line, so it can
+// be piped in by scripts.
+func promptPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Print(prompt)
+		line, err := stdin.ReadString('\n')
+		fmt.Println()
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return "", errors.New("no password given")
+			}
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Print(prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// newPassword asks for a new password twice and returns its hash.
+func newPassword() (string, error) {
+	password, err := promptPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	if len(password) < auth.MinPasswordLen {
+		return "", fmt.Errorf("password must be at least %d characters", auth.MinPasswordLen)
+	}
+	again, err := promptPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != again {
+		return "", errors.New("passwords do not match")
+	}
+	return auth.HashPassword(password)
+}
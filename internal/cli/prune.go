@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func PruneFlags(fs *flag.FlagSet) {
+	fs.Bool("dry-run", false, "report what would be deleted without deleting anything")
+}
+
+func HandlerPrune(s *State, cmd Command) error {
+	if err := requireKey(s); err != nil {
+		return err
+	}
+	dryRun := cmd.boolFlag("dry-run")
+	n, err := prunePosts(s.Ctx, s, dryRun, true)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("Would delete %d posts\n", n)
+	} else {
+		fmt.Printf("Deleted %d posts\n", n)
+	}
+	return nil
+}
+
+// prunePosts applies the configured retention policy to every feed and
+// returns the number of posts deleted, or that would be deleted when dryRun
+// is set. Starred posts are never deleted.
+func prunePosts(ctx context.Context, s *State, dryRun, verbose bool) (int64, error) {
+	retention := s.Config.Retention
+
+	feeds, err := s.DB.ListFeeds(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list feeds: %w", err)
+	}
+
+	var total int64
+	for _, feed := range feeds {
+		policy := retention.PolicyFor(feed.Url)
+		if policy.MaxAgeDays <= 0 && policy.MaxPostsPerFeed <= 0 {
+			continue
+		}
+
+		params := database.GetPrunablePostsParams{
+			FeedID:     feed.ID,
+			KeepUnread: retention.KeepUnread,
+		}
+		if policy.MaxAgeDays > 0 {
+			cutoff := time.Now().AddDate(0, 0, -policy.MaxAgeDays)
+			params.OlderThan = sql.NullTime{Time: cutoff, Valid: true}
+		}
+		if policy.MaxPostsPerFeed > 0 {
+			params.MaxPosts = sql.NullInt64{Int64: int64(policy.MaxPostsPerFeed), Valid: true}
+		}
+
+		posts, err := s.DB.GetPrunablePosts(ctx, params)
+		if err != nil {
+			return total, fmt.Errorf("failed to find posts to prune for %s: %w", feed.Name, err)
+		}
+		if len(posts) == 0 {
+			continue
+		}
+
+		if verbose {
+			fmt.Printf("%s: %d posts\n", feed.Name, len(posts))
+			if dryRun {
+				for _, post := range posts {
+					fmt.Printf("- %s (%s)\n", post.Title, post.Url)
+				}
+			}
+		}
+
+		if dryRun {
+			total += int64(len(posts))
+			continue
+		}
+
+		ids := make([]uuid.UUID, len(posts))
+		for i, post := range posts {
+			ids[i] = post.ID
+		}
+		n, err := s.DB.DeletePosts(ctx, ids)
+		if err != nil {
+			return total, fmt.Errorf("failed to prune posts for %s: %w", feed.Name, err)
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// snapshot is the JSON backup written before a reset. It holds every row
+// the reset deletes, except for password hashes, key hashes and webhook
+// secrets, so a leaked snapshot gives no one access.
+type snapshot struct {
+	TakenAt        time.Time                     `json:"taken_at"`
+	Scope          string                        `json:"scope"`
+	Users          []snapshotUser                `json:"users,omitempty"`
+	Feeds          []database.Feed               `json:"feeds,omitempty"`
+	FeedFollows    []database.FeedFollow         `json:"feed_follows,omitempty"`
+	Tags           []database.Tag                `json:"tags,omitempty"`
+	FeedFollowTags []database.FeedFollowTag      `json:"feed_follow_tags,omitempty"`
+	Posts          []database.Post               `json:"posts,omitempty"`
+	UserPostState  []database.UserPostState      `json:"user_post_state,omitempty"`
+	StarredPosts   []database.StarredPost        `json:"starred_posts,omitempty"`
+	APIKeys        []snapshotAPIKey              `json:"api_keys,omitempty"`
+	Webhooks       []snapshotWebhook             `json:"webhooks,omitempty"`
+	Digests        []database.DigestSubscription `json:"digest_subscriptions,omitempty"`
+}
+
+// snapshotUser is a users row without the password hash.
+type snapshotUser struct {
+	ID        uuid.UUID      `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Name      string         `json:"name"`
+	Email     sql.NullString `json:"email"`
+}
+
+// snapshotAPIKey is an api_keys row without the key hash.
+type snapshotAPIKey struct {
+	ID         uuid.UUID    `json:"id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scope      string       `json:"scope"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+}
+
+// snapshotWebhook is a webhooks row without the signing secret.
+type snapshotWebhook struct {
+	ID        uuid.UUID      `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Url       string         `json:"url"`
+	Format    string         `json:"format"`
+	FeedID    uuid.NullUUID  `json:"feed_id"`
+	Tag       sql.NullString `json:"tag"`
+	Keyword   sql.NullString `json:"keyword"`
+}
+
+func newSnapshotWebhook(w database.Webhook) snapshotWebhook {
+	return snapshotWebhook{
+		ID:        w.ID,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+		UserID:    w.UserID,
+		Url:       w.Url,
+		Format:    w.Format,
+		FeedID:    w.FeedID,
+		Tag:       w.Tag,
+		Keyword:   w.Keyword,
+	}
+}
+
+// summary describes the non-empty tables in the snapshot, e.g.
+// "2 users, 5 feeds".
+func (snap snapshot) summary() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{
+		{len(snap.Users), "users"},
+		{len(snap.Feeds), "feeds"},
+		{len(snap.FeedFollows), "follows"},
+		{len(snap.Tags), "tags"},
+		{len(snap.Posts), "posts"},
+		{len(snap.UserPostState), "read states"},
+		{len(snap.StarredPosts), "starred posts"},
+		{len(snap.APIKeys), "API keys"},
+		{len(snap.Webhooks), "webhooks"},
+		{len(snap.Digests), "digest subscriptions"},
+	} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resetScope knows which rows a scoped reset deletes, so it can back them
+// up first. Deleting a table also deletes the rows that cascade from it.
+type resetScope struct {
+	load  func(ctx context.Context, q database.Querier, snap *snapshot) error
+	reset func(ctx context.Context, q database.Querier) error
+}
+
+var resetScopes = map[string]resetScope{
+	"all": {
+		load: loadAll(loadUsers, loadFeeds, loadFollows, loadTags, loadPosts, loadStarred, loadAPIKeys, loadWebhooks, loadDigests),
+		reset: func(ctx context.Context, q database.Querier) error {
+			return q.ResetUsers(ctx)
+		},
+	},
+	"feeds": {
+		load: loadAll(loadFeeds, loadFollows, loadTags, loadPosts, loadFeedWebhooks),
+		reset: func(ctx context.Context, q database.Querier) error {
+			if err := q.ResetFeeds(ctx); err != nil {
+				return err
+			}
+			// Tags only exist to group follows, which are now gone.
+			return q.ResetTags(ctx)
+		},
+	},
+	"follows": {
+		load: loadAll(loadFollows, loadTags),
+		reset: func(ctx context.Context, q database.Querier) error {
+			if err := q.ResetFeedFollows(ctx); err != nil {
+				return err
+			}
+			return q.ResetTags(ctx)
+		},
+	},
+	"posts": {
+		load: loadAll(loadPosts),
+		reset: func(ctx context.Context, q database.Querier) error {
+			return q.ResetPosts(ctx)
+		},
+	},
+}
+
+func ResetFlags(fs *flag.FlagSet) {
+	fs.Bool("yes", false, "reset without asking for confirmation")
+	fs.Bool("no-snapshot", false, "don't back up the deleted rows")
+	fs.String("snapshot", "", "write the backup to this `file` instead of ~/.gator/snapshots")
+}
+
+func HandlerReset(s *State, cmd Command) error {
+	// Every scope deletes other users' data, and "all" their accounts and
+	// keys too, which no single user's key should allow.
+	if s.Config.RequireAPIKey {
+		return errors.New("reset is disabled while require_api_key is set")
+	}
+	scopeName := "all"
+	if len(cmd.Args) > 0 {
+		scopeName = cmd.Args[0]
+	}
+	scope, ok := resetScopes[scopeName]
+	if !ok {
+		return fmt.Errorf("unknown reset scope %q (expected all, feeds, follows or posts)", scopeName)
+	}
+
+	ctx := s.Ctx
+	if !cmd.boolFlag("yes") {
+		var preview snapshot
+		if err := scope.load(ctx, s.DB, &preview); err != nil {
+			return err
+		}
+		ok, err := confirm(fmt.Sprintf("This will permanently delete %s. Continue?", preview.summary()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	path := cmd.stringFlag("snapshot")
+	if path == "" && !cmd.boolFlag("no-snapshot") {
+		var err error
+		path, err = defaultSnapshotPath(scopeName)
+		if err != nil {
+			return err
+		}
+	}
+
+	var snap snapshot
+	written := false
+	err := s.DB.ExecTx(ctx, func(q database.Querier) error {
+		snap = snapshot{TakenAt: time.Now(), Scope: scopeName}
+		if err := scope.load(ctx, q, &snap); err != nil {
+			return err
+		}
+		// Write the backup before deleting anything so a failed write
+		// rolls the reset back.
+		if path != "" {
+			if err := writeSnapshot(path, snap); err != nil {
+				return err
+			}
+			written = true
+		}
+		if err := scope.reset(ctx, q); err != nil {
+			return fmt.Errorf("failed to reset database: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// Nothing was deleted, so the snapshot backs up nothing.
+		if written {
+			os.Remove(path)
+		}
+		return err
+	}
+
+	if path != "" {
+		fmt.Printf("Saved snapshot to %s\n", path)
+	}
+	fmt.Printf("Deleted %s\n", snap.summary())
+	return nil
+}
+
+func defaultSnapshotPath(scope string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	name := fmt.Sprintf("reset-%s-%s.json", scope, time.Now().Format("20060102-150405"))
+	return filepath.Join(homeDir, ".gator", "snapshots", name), nil
+}
+
+func writeSnapshot(path string, snap snapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+type snapshotLoader func(ctx context.Context, q database.Querier, snap *snapshot) error
+
+func loadAll(loaders ...snapshotLoader) snapshotLoader {
+	return func(ctx context.Context, q database.Querier, snap *snapshot) error {
+		for _, load := range loaders {
+			if err := load(ctx, q, snap); err != nil {
+				return fmt.Errorf("failed to take snapshot: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+func loadUsers(ctx context.Context, q database.Querier, snap *snapshot) error {
+	users, err := q.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		snap.Users = append(snap.Users, snapshotUser{
+			ID:        u.ID,
+			CreatedAt: u.CreatedAt,
+			UpdatedAt: u.UpdatedAt,
+			Name:      u.Name,
+			Email:     u.Email,
+		})
+	}
+	return nil
+}
+
+func loadFeeds(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	snap.Feeds, err = q.ListFeeds(ctx)
+	return err
+}
+
+func loadFollows(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	if snap.FeedFollows, err = q.ListFeedFollows(ctx); err != nil {
+		return err
+	}
+	snap.FeedFollowTags, err = q.ListFeedFollowTags(ctx)
+	return err
+}
+
+func loadTags(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	snap.Tags, err = q.ListTags(ctx)
+	return err
+}
+
+func loadPosts(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	if snap.Posts, err = q.ListPosts(ctx); err != nil {
+		return err
+	}
+	snap.UserPostState, err = q.ListUserPostStates(ctx)
+	return err
+}
+
+func loadStarred(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	snap.StarredPosts, err = q.ListStarredPosts(ctx)
+	return err
+}
+
+func loadAPIKeys(ctx context.Context, q database.Querier, snap *snapshot) error {
+	keys, err := q.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		snap.APIKeys = append(snap.APIKeys, snapshotAPIKey{
+			ID:         k.ID,
+			CreatedAt:  k.CreatedAt,
+			UpdatedAt:  k.UpdatedAt,
+			UserID:     k.UserID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scope:      k.Scope,
+			LastUsedAt: k.LastUsedAt,
+		})
+	}
+	return nil
+}
+
+func loadWebhooks(ctx context.Context, q database.Querier, snap *snapshot) error {
+	webhooks, err := q.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		snap.Webhooks = append(snap.Webhooks, newSnapshotWebhook(w))
+	}
+	return nil
+}
+
+func loadDigests(ctx context.Context, q database.Querier, snap *snapshot) (err error) {
+	snap.Digests, err = q.ListDigestSubscriptions(ctx)
+	return err
+}
+
+// loadFeedWebhooks loads the webhooks limited to one feed, which are
+// deleted along with it.
+func loadFeedWebhooks(ctx context.Context, q database.Querier, snap *snapshot) error {
+	webhooks, err := q.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		if w.FeedID.Valid {
+			snap.Webhooks = append(snap.Webhooks, newSnapshotWebhook(w))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+)
+
+func TestResetSnapshotOmitsCredentials(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		s := newTestState(t, db)
+		register(t, s, "alice")
+		secret := strings.TrimSpace(mustRun(t, s, "", "webhook", "add", "https://203.0.113.10/gator"))
+
+		path := filepath.Join(t.TempDir(), "snapshot.json")
+		mustRun(t, s, "", "reset", "--yes", "--snapshot", path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap := string(data)
+		for _, want := range []string{`"name": "alice"`, "https://203.0.113.10/gator"} {
+			if !strings.Contains(snap, want) {
+				t.Errorf("snapshot does not contain %s:\n%s", want, snap)
+			}
+		}
+		for _, secret := range []string{"password_hash", "$2a$", "key_hash", `"secret"`, secret} {
+			if strings.Contains(snap, secret) {
+				t.Errorf("snapshot contains %s:\n%s", secret, snap)
+			}
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+
+	"github.com/google/uuid"
+)
+
+var errInjected = errors.New("injected failure")
+
+// failingStore makes CreateFeedFollow fail, or CreatePost fail once
+// failPostAfter posts have been created, both outside and inside
+// transactions. CountDueFeeds fails while failCount is set, ResetTags
+// while failResetTags is, and SetDigestSent for the user failDigestFor.
+type failingStore struct {
+	database.Store
+	failFollow    bool
+	failCount     bool
+	failResetTags bool
+	failDigestFor uuid.UUID
+	failPostAfter int
+	posts         int
+}
+
+func (f *failingStore) querier(q database.Querier) database.Querier {
+	return &failingQuerier{Querier: q, store: f}
+}
+
+func (f *failingStore) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	return f.Store.ExecTx(ctx, func(q database.Querier) error {
+		return fn(f.querier(q))
+	})
+}
+
+func (f *failingStore) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	return f.querier(f.Store).CreateFeedFollow(ctx, arg)
+}
+
+func (f *failingStore) CreatePost(ctx context.Context, arg database.CreatePostParams) (int64, error) {
+	return f.querier(f.Store).CreatePost(ctx, arg)
+}
+
+func (f *failingStore) CountDueFeeds(ctx context.Context, before sql.NullTime) (int64, error) {
+	if f.failCount {
+		return 0, errInjected
+	}
+	return f.Store.CountDueFeeds(ctx, before)
+}
+
+func (f *failingStore) SetDigestSent(ctx context.Context, arg database.SetDigestSentParams) error {
+	if arg.UserID == f.failDigestFor {
+		return errInjected
+	}
+	return f.Store.SetDigestSent(ctx, arg)
+}
+
+type failingQuerier struct {
+	database.Querier
+	store *failingStore
+}
+
+func (q *failingQuerier) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	if q.store.failFollow {
+		return database.CreateFeedFollowRow{}, errInjected
+	}
+	return q.Querier.CreateFeedFollow(ctx, arg)
+}
+
+func (q *failingQuerier) CreatePost(ctx context.Context, arg database.CreatePostParams) (int64, error) {
+	if q.store.failPostAfter > 0 && q.store.posts == q.store.failPostAfter {
+		return 0, errInjected
+	}
+	q.store.posts++
+	return q.Querier.CreatePost(ctx, arg)
+}
+
+func (q *failingQuerier) ResetTags(ctx context.Context) error {
+	if q.store.failResetTags {
+		return errInjected
+	}
+	return q.Querier.ResetTags(ctx)
+}
+
+func TestAddFeedRollback(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		store := &failingStore{Store: db}
+		s := newTestState(t, store)
+		register(t, s, "alice")
+
+		store.failFollow = true
+		_, err := run(t, s, "", "addfeed", "Blog", "https://blog.example.com/rss")
+		checkError(t, err, "failed to automatically follow feed")
+		if !errors.Is(err, errInjected) {
+			t.Errorf("got %v, want it to wrap the injected error", err)
+		}
+
+		if _, err := db.GetFeedByURL(ctx, "https://blog.example.com/rss"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetFeedByURL after rollback: got %v, want sql.ErrNoRows", err)
+		}
+		feeds, err := db.GetAllFeeds(ctx)
+		if err != nil {
+			t.Fatalf("GetAllFeeds: %v", err)
+		}
+		if len(feeds) != 0 {
+			t.Errorf("%d orphaned feeds left after rollback", len(feeds))
+		}
+
+		// The same feed can be added once the follow succeeds.
+		store.failFollow = false
+		mustRun(t, s, "", "addfeed", "Blog", "https://blog.example.com/rss")
+	})
+}
+
+func TestScrapeFeedsRollback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSS)
+	}))
+	defer srv.Close()
+
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		store := &failingStore{Store: db, failPostAfter: 1}
+		s := newTestState(t, store)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Test", srv.URL)
+		s.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		err := scrapeFeeds(ctx, s, time.Hour)
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("scrapeFeeds: got %v, want the injected error", err)
+		}
+		if _, err := db.GetPostByURL(ctx, "https://example.com/1"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("post inserted before the failure was kept: %v", err)
+		}
+
+		// Nothing of the failed batch is left to collide with the retry.
+		store.failPostAfter = 0
+		if err := scrapeFeeds(ctx, s, time.Hour); err != nil {
+			t.Fatalf("scrapeFeeds: %v", err)
+		}
+		for _, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"} {
+			if _, err := db.GetPostByURL(ctx, url); err != nil {
+				t.Errorf("GetPostByURL(%s) after retry: %v", url, err)
+			}
+		}
+	})
+}
+
+func TestScrapeFeedsCountFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSS)
+	}))
+	defer srv.Close()
+
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		store := &failingStore{Store: db, failCount: true}
+		s := newTestState(t, store)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Test", srv.URL)
+		s.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+		// Counting due feeds only feeds a gauge, so the feed is still fetched.
+		if err := scrapeFeeds(ctx, s, time.Hour); err != nil {
+			t.Fatalf("scrapeFeeds: %v", err)
+		}
+		if _, err := db.GetPostByURL(ctx, "https://example.com/1"); err != nil {
+			t.Errorf("GetPostByURL: %v", err)
+		}
+	})
+}
+
+func TestResetRollback(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		store := &failingStore{Store: db}
+		s := newTestState(t, store)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Blog", "https://blog.example.com/rss")
+		mustRun(t, s, "", "tag", "https://blog.example.com/rss", "news")
+
+		// Resetting feeds deletes them, then fails deleting the tags.
+		store.failResetTags = true
+		path := filepath.Join(t.TempDir(), "snapshot.json")
+		_, err := run(t, s, "", "reset", "--yes", "--snapshot", path, "feeds")
+		if !errors.Is(err, errInjected) {
+			t.Fatalf("reset: got %v, want the injected error", err)
+		}
+
+		if _, err := db.GetFeedByURL(ctx, "https://blog.example.com/rss"); err != nil {
+			t.Errorf("GetFeedByURL after rollback: %v", err)
+		}
+		tags, err := db.ListFeedFollowTags(ctx)
+		if err != nil {
+			t.Fatalf("ListFeedFollowTags: %v", err)
+		}
+		if len(tags) != 1 {
+			t.Errorf("%d feed follow tags left after rollback, want 1", len(tags))
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("snapshot of the failed reset was left behind: %v", err)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"gator/internal/api"
+	"gator/internal/events"
+	"gator/internal/metrics"
+)
+
+// shutdownTimeout is how long in-flight requests, and agg's in-flight
+// fetches, get to finish after SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+func ServeFlags(fs *flag.FlagSet) {
+	fs.String("addr", ":8080", "`address` to listen on")
+}
+
+func HandlerServe(s *State, cmd Command) error {
+	srv := &http.Server{
+		Addr:              cmd.stringFlag("addr"),
+		Handler:           api.NewServer(s.DB, s.Logger),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(s.Logger.Handler(), slog.LevelError),
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		s.Logger.Info("listening", "addr", srv.Addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-s.Ctx.Done():
+	}
+
+	s.Logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(s.Ctx), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down cleanly: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// streamEvents serves the Server-Sent Events stream of new posts on addr
+// and connects it to scrapeFeeds through s.Events. The listener is opened
+// before returning so that a bad or busy address is reported at once.
+func streamEvents(s *State, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.Events = events.NewBus()
+	srv := &http.Server{
+		Handler:           api.NewEventServer(s.DB, s.Events, s.Logger),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(s.Logger.Handler(), slog.LevelError),
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			s.Logger.Error("event stream stopped", "error", err)
+		}
+	}()
+	s.Logger.Info("streaming new posts", "addr", ln.Addr().String())
+	return nil
+}
+
+// serveMetrics serves agg's Prometheus metrics at /metrics on addr. Like
+// streamEvents, it listens before returning.
+func serveMetrics(s *State, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          slog.NewLogLogger(s.Logger.Handler(), slog.LevelError),
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			s.Logger.Error("metrics server stopped", "error", err)
+		}
+	}()
+	s.Logger.Info("serving metrics", "url", "http://"+ln.Addr().String()+"/metrics")
+	return nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func HandlerTagLogged(s *State, cmd Command, user database.User) error {
+	feedURL := cmd.Args[0]
+	tagName := strings.TrimSpace(cmd.Args[1])
+	if tagName == "" {
+		return errors.New("tag cannot be empty")
+	}
+
+	follow, err := getFeedFollowByURL(s.Ctx, s, user, feedURL)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	tag, err := s.DB.CreateTag(s.Ctx, database.CreateTagParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		Name:      tagName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	err = s.DB.AddFeedFollowTag(s.Ctx, database.AddFeedFollowTagParams{
+		FeedFollowID: follow.ID,
+		TagID:        tag.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag feed: %w", err)
+	}
+
+	fmt.Printf("Tagged %s with %s\n", feedURL, tag.Name)
+	return nil
+}
+
+func HandlerUntagLogged(s *State, cmd Command, user database.User) error {
+	feedURL := cmd.Args[0]
+	tagName := strings.TrimSpace(cmd.Args[1])
+
+	follow, err := getFeedFollowByURL(s.Ctx, s, user, feedURL)
+	if err != nil {
+		return err
+	}
+
+	n, err := s.DB.RemoveFeedFollowTag(s.Ctx, database.RemoveFeedFollowTagParams{
+		FeedFollowID: follow.ID,
+		Name:         tagName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to untag feed: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("feed %s is not tagged with %s", feedURL, tagName)
+	}
+
+	if err := s.DB.DeleteUnusedTags(s.Ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to clean up tags: %w", err)
+	}
+
+	fmt.Printf("Removed tag %s from %s\n", tagName, feedURL)
+	return nil
+}
+
+func getFeedFollowByURL(ctx context.Context, s *State, user database.User, feedURL string) (database.FeedFollow, error) {
+	follow, err := s.DB.GetFeedFollowForUserByURL(ctx, database.GetFeedFollowForUserByURLParams{
+		UserID: user.ID,
+		Url:    feedURL,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return follow, fmt.Errorf("you are not following a feed with URL %s", feedURL)
+		}
+		return follow, fmt.Errorf("failed to get feed follow: %w", err)
+	}
+	return follow, nil
+}
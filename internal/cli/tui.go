@@ -0,0 +1,10 @@
+package cli
+
+import (
+	"gator/internal/database"
+	"gator/internal/tui"
+)
+
+func HandlerTUILogged(s *State, cmd Command, user database.User) error {
+	return tui.Run(s.Ctx, s.DB, user)
+}
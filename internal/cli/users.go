@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+)
+
+func DelUserFlags(fs *flag.FlagSet) {
+	fs.Bool("yes", false, "delete without asking for confirmation")
+}
+
+func HandlerDelUser(s *State, cmd Command) error {
+	name := cmd.Args[0]
+
+	ctx := s.Ctx
+	user, err := getUserByName(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	if err := checkSelf(s, user, "delete"); err != nil {
+		return err
+	}
+
+	if !cmd.boolFlag("yes") {
+		ok, err := confirm(fmt.Sprintf("Delete user %s with their follows, tags, starred posts and the feeds they added?", user.Name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	// Everything the user owns cascades through the foreign keys.
+	if err := s.DB.DeleteUser(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if s.Config.CurrentUserName == user.Name {
+		// The user's API keys were deleted with them.
+		if err := s.Config.SetLogin("", ""); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Deleted user %s\n", user.Name)
+	return nil
+}
+
+func HandlerRenameUser(s *State, cmd Command) error {
+	oldName := cmd.Args[0]
+	newName := strings.TrimSpace(cmd.Args[1])
+	if newName == "" {
+		return errors.New("username cannot be empty")
+	}
+
+	ctx := s.Ctx
+	user, err := getUserByName(ctx, s, oldName)
+	if err != nil {
+		return err
+	}
+	if err := checkSelf(s, user, "rename"); err != nil {
+		return err
+	}
+
+	_, err = s.DB.GetUser(ctx, newName)
+	if err == nil {
+		return fmt.Errorf("user %s already exists", newName)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check username: %w", err)
+	}
+
+	renamed, err := s.DB.RenameUser(ctx, database.RenameUserParams{
+		ID:   user.ID,
+		Name: newName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename user: %w", err)
+	}
+	if s.Config.CurrentUserName == oldName {
+		if err := s.Config.SetUser(renamed.Name); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Renamed user %s to %s\n", oldName, renamed.Name)
+	return nil
+}
+
+type userRow struct {
+	Name       string    `json:"name"`
+	Current    bool      `json:"current"`
+	Follows    int64     `json:"follows"`
+	FeedsAdded int64     `json:"feeds_added"`
+	LastActive time.Time `json:"last_active"`
+}
+
+func HandlerUsers(s *State, cmd Command) error {
+	out, err := cmd.renderer()
+	if err != nil {
+		return err
+	}
+
+	users, err := s.DB.GetUserStats(s.Ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	rows := make([]userRow, len(users))
+	for i, u := range users {
+		rows[i] = userRow{
+			Name:       u.Name,
+			Current:    u.Name == s.Config.CurrentUserName,
+			Follows:    u.FollowCount,
+			FeedsAdded: u.FeedCount,
+			LastActive: u.LastActiveAt.Local(),
+		}
+	}
+	return out.Render(rows)
+}
+
+func getUserByName(ctx context.Context, s *State, name string) (database.User, error) {
+	user, err := s.DB.GetUser(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return user, fmt.Errorf("user %s does not exist", name)
+		}
+		return user, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// checkSelf stops users from acting on other accounts when API keys are
+// required.
+func checkSelf(s *State, user database.User, action string) error {
+	if !s.Config.RequireAPIKey {
+		return nil
+	}
+	me, err := authenticate(s)
+	if err != nil {
+		return err
+	}
+	if me.ID != user.ID {
+		return fmt.Errorf("you can only %s your own account", action)
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// deliveryBatch is how many due webhook deliveries agg sends per round.
+	deliveryBatch = 50
+	// deliveryWorkers is how many deliveries are sent at once.
+	deliveryWorkers = 10
+	// deliveryRound is how long a round may go on starting deliveries.
+	// With each send limited to 10 seconds, a round of slow endpoints holds
+	// up agg for at most 40 seconds.
+	deliveryRound = 30 * time.Second
+)
+
+func WebhookFlags(fs *flag.FlagSet) {
+	fs.String("feed", "", "add: only send posts from the feed with this `URL`")
+	fs.String("tag", "", "add: only send posts from feeds with this `tag`")
+	fs.String("keyword", "", "add: only send posts whose title or description contains this `text`")
+	fs.String("format", webhook.FormatJSON, "add: payload `format`: json, slack or discord")
+	fs.Bool("allow-private", false, "add: allow a URL on a loopback, private or link-local address")
+	fs.Int("limit", 20, "log: maximum number of deliveries to show")
+}
+
+type webhookRow struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Format    string    `json:"format"`
+	Feed      string    `json:"feed"`
+	Tag       string    `json:"tag"`
+	Keyword   string    `json:"keyword"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type deliveryRow struct {
+	CreatedAt   time.Time  `json:"created_at"`
+	Webhook     string     `json:"webhook"`
+	Post        string     `json:"post"`
+	Status      string     `json:"status"`
+	Attempts    int32      `json:"attempts"`
+	Response    *int32     `json:"response"`
+	Error       string     `json:"error"`
+	NextAttempt *time.Time `json:"next_attempt"`
+}
+
+func HandlerWebhookLogged(s *State, cmd Command, user database.User) error {
+	ctx := s.Ctx
+
+	switch cmd.Args[0] {
+	case "add":
+		if len(cmd.Args) < 2 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("webhook URL is required")}
+		}
+		return addWebhook(ctx, s, cmd, user, cmd.Args[1])
+
+	case "list":
+		out, err := cmd.renderer()
+		if err != nil {
+			return err
+		}
+		webhooks, err := s.DB.GetWebhooksForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get webhooks: %w", err)
+		}
+		if len(webhooks) == 0 {
+			out.Notef("No webhooks found.")
+		}
+		rows := make([]webhookRow, len(webhooks))
+		for i, w := range webhooks {
+			rows[i] = webhookRow{
+				ID:        w.ID,
+				URL:       w.Url,
+				Format:    w.Format,
+				Feed:      w.FeedUrl.String,
+				Tag:       w.Tag.String,
+				Keyword:   w.Keyword.String,
+				CreatedAt: w.CreatedAt,
+			}
+		}
+		return out.Render(rows)
+
+	case "remove":
+		if len(cmd.Args) < 2 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("webhook ID is required")}
+		}
+		id := cmd.Args[1]
+		n, err := s.DB.DeleteWebhook(ctx, database.DeleteWebhookParams{UserID: user.ID, Ref: id})
+		if err != nil {
+			return fmt.Errorf("failed to remove webhook: %w", err)
+		}
+		if n == 0 {
+			return fmt.Errorf("no webhook %s found for %s", id, user.Name)
+		}
+		fmt.Printf("Removed webhook %s\n", id)
+		return nil
+
+	case "log":
+		out, err := cmd.renderer()
+		if err != nil {
+			return err
+		}
+		limit := cmd.intFlag("limit")
+		if limit < 1 {
+			return &UsageError{Command: cmd.Name, Err: errors.New("--limit must be at least 1")}
+		}
+		deliveries, err := s.DB.GetWebhookDeliveriesForUser(ctx, database.GetWebhookDeliveriesForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get webhook deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			out.Notef("No webhook deliveries found.")
+		}
+		rows := make([]deliveryRow, len(deliveries))
+		for i, d := range deliveries {
+			rows[i] = deliveryRow{
+				CreatedAt: d.CreatedAt,
+				Webhook:   d.WebhookUrl,
+				Post:      d.PostTitle.String,
+				Status:    d.Status,
+				Attempts:  d.Attempts,
+				Error:     d.LastError.String,
+			}
+			if d.ResponseStatus.Valid {
+				rows[i].Response = &d.ResponseStatus.Int32
+			}
+			if d.Status == webhook.StatusPending {
+				rows[i].NextAttempt = &d.NextAttemptAt
+			}
+		}
+		return out.Render(rows)
+
+	default:
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("unknown webhook action %q", cmd.Args[0])}
+	}
+}
+
+func addWebhook(ctx context.Context, s *State, cmd Command, user database.User, endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: must be an http or https URL", endpoint)
+	}
+	if !cmd.boolFlag("allow-private") {
+		if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+			return err
+		}
+	}
+	format := cmd.stringFlag("format")
+	if !slices.Contains(webhook.Formats, format) {
+		return &UsageError{Command: cmd.Name, Err: fmt.Errorf("invalid --format %q: must be %s", format, strings.Join(webhook.Formats, ", "))}
+	}
+
+	var feedID uuid.NullUUID
+	if feedURL := cmd.stringFlag("feed"); feedURL != "" {
+		ff, err := s.DB.GetFeedFollowForUserByURL(ctx, database.GetFeedFollowForUserByURLParams{UserID: user.ID, Url: feedURL})
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%s does not follow %s", user.Name, feedURL)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get feed follow: %w", err)
+		}
+		feedID = uuid.NullUUID{UUID: ff.FeedID, Valid: true}
+	}
+
+	tag, keyword := cmd.stringFlag("tag"), cmd.stringFlag("keyword")
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	w, err := s.DB.CreateWebhook(ctx, database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		Url:       u.String(),
+		Secret:    secret,
+		Format:    format,
+		FeedID:    feedID,
+		Tag:       sql.NullString{String: tag, Valid: tag != ""},
+		Keyword:   sql.NullString{String: keyword, Valid: keyword != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	fmt.Println(secret)
+	fmt.Fprintf(os.Stderr, "Created webhook %s. Requests are signed with the secret above, which is shown only once.\n", w.ID)
+	return nil
+}
+
+// checkWebhookHost rejects hosts with a loopback, private, link-local or
+// unspecified address, such as cloud metadata endpoints, so that webhooks
+// can't make agg send requests into the network it runs in.
+func checkWebhookHost(ctx context.Context, host string) error {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	} else {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return fmt.Errorf("failed to resolve webhook host: %w", err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return fmt.Errorf("webhook host %s has the non-public address %s: pass --allow-private to use it anyway", host, ip)
+		}
+	}
+	return nil
+}
+
+// enqueueWebhooks queues a delivery of each post to every webhook that
+// wants it. It runs in the transaction that stores the posts, so a post is
+// never saved without its deliveries.
+func enqueueWebhooks(ctx context.Context, q database.Querier, feed database.Feed, posts []database.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	webhooks, err := q.GetWebhooksForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	for _, w := range webhooks {
+		for _, p := range posts {
+			if !webhook.Matches(w, p) {
+				continue
+			}
+			payload, err := webhook.Payload(w, p, feed)
+			if err != nil {
+				return err
+			}
+			_, err = q.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+				ID:            uuid.New(),
+				CreatedAt:     p.CreatedAt,
+				UpdatedAt:     p.CreatedAt,
+				WebhookID:     w.ID,
+				PostID:        uuid.NullUUID{UUID: p.ID, Valid: true},
+				Payload:       string(payload),
+				NextAttemptAt: p.CreatedAt,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to queue webhook delivery: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// deliverWebhooks sends the deliveries that are due, deliveryWorkers at a
+// time, and records the outcome of each, scheduling a retry for those that
+// failed. Deliveries not started within deliveryRound stay due for the next
+// round without counting as an attempt.
+func deliverWebhooks(ctx context.Context, s *State) error {
+	due, err := s.DB.GetDueWebhookDeliveries(ctx, database.GetDueWebhookDeliveriesParams{
+		Now:   time.Now(),
+		Limit: deliveryBatch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	deadline := time.Now().Add(deliveryRound)
+	jobs := make(chan database.GetDueWebhookDeliveriesRow)
+	errs := make(chan error, len(due))
+	var wg sync.WaitGroup
+	for range min(deliveryWorkers, len(due)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range jobs {
+				errs <- deliverWebhook(ctx, s, d)
+			}
+		}()
+	}
+	for _, d := range due {
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			break
+		}
+		jobs <- d
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	var failed []error
+	for err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return errors.Join(failed...)
+}
+
+// deliverWebhook sends one delivery and records the outcome.
+func deliverWebhook(ctx context.Context, s *State, d database.GetDueWebhookDeliveriesRow) error {
+	status, sendErr := webhook.Send(ctx, d.Url, d.Secret, d.ID, []byte(d.Payload))
+	now := time.Now()
+	update := database.UpdateWebhookDeliveryParams{
+		ID:             d.ID,
+		UpdatedAt:      now,
+		Status:         webhook.StatusDelivered,
+		Attempts:       d.Attempts + 1,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: status != 0},
+		DeliveredAt:    sql.NullTime{Time: now, Valid: true},
+	}
+	if sendErr != nil {
+		update.LastError = sql.NullString{String: sendErr.Error(), Valid: true}
+		update.DeliveredAt = sql.NullTime{}
+		update.Status = webhook.StatusFailed
+		if next, ok := webhook.Retry(update.Attempts, status, now); ok {
+			update.Status = webhook.StatusPending
+			update.NextAttemptAt = next
+		}
+		s.Logger.Warn("webhook delivery failed",
+			"delivery_id", d.ID,
+			"url", d.Url,
+			"attempt", update.Attempts,
+			"status", status,
+			"error", sendErr,
+		)
+	}
+	if err := s.DB.UpdateWebhookDelivery(ctx, update); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+	"gator/internal/database/memory"
+	"gator/internal/webhook"
+)
+
+func TestDeliverWebhooks(t *testing.T) {
+	feed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testRSS)
+	}))
+	defer feed.Close()
+
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		s := newTestState(t, db)
+		register(t, s, "alice")
+		mustRun(t, s, "", "addfeed", "Test", feed.URL)
+
+		// Each endpoint waits until all three requests have arrived, so the
+		// round only finishes quickly if they are sent at once.
+		statuses := []int{http.StatusOK, http.StatusInternalServerError, http.StatusNotFound}
+		var arrived sync.WaitGroup
+		arrived.Add(len(statuses))
+		all := make(chan struct{})
+		go func() { arrived.Wait(); close(all) }()
+		var serial atomic.Bool
+
+		urls := map[int]string{}
+		for _, status := range statuses {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				arrived.Done()
+				select {
+				case <-all:
+				case <-time.After(5 * time.Second):
+					serial.Store(true)
+				}
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+			urls[status] = srv.URL
+			mustRun(t, s, "", "webhook", "--keyword", "first", "--allow-private", "add", srv.URL)
+		}
+
+		if err := scrapeFeeds(ctx, s, time.Hour); err != nil {
+			t.Fatalf("scrapeFeeds: %v", err)
+		}
+		start := time.Now()
+		if err := deliverWebhooks(ctx, s); err != nil {
+			t.Fatalf("deliverWebhooks: %v", err)
+		}
+		if serial.Load() {
+			t.Error("deliveries were sent one at a time")
+		}
+
+		var rows []deliveryRow
+		out := mustRun(t, s, "", "webhook", "--output", "json", "log")
+		if err := json.Unmarshal([]byte(out), &rows); err != nil {
+			t.Fatalf("webhook log: %v\n%s", err, out)
+		}
+		if len(rows) != len(statuses) {
+			t.Fatalf("got %d deliveries, want %d: %+v", len(rows), len(statuses), rows)
+		}
+		got := map[string]deliveryRow{}
+		for _, row := range rows {
+			got[row.Webhook] = row
+		}
+
+		tests := []struct {
+			status  int
+			want    string
+			retried bool
+		}{
+			{http.StatusOK, webhook.StatusDelivered, false},
+			{http.StatusInternalServerError, webhook.StatusPending, true},
+			{http.StatusNotFound, webhook.StatusFailed, false},
+		}
+		for _, tt := range tests {
+			row, ok := got[urls[tt.status]]
+			if !ok {
+				t.Errorf("no delivery to the %d endpoint", tt.status)
+				continue
+			}
+			if row.Status != tt.want || row.Attempts != 1 || row.Post != "First" {
+				t.Errorf("%d endpoint: got %s after %d attempts of %q, want %s after 1 attempt of \"First\"",
+					tt.status, row.Status, row.Attempts, row.Post, tt.want)
+			}
+			if row.Response == nil || int(*row.Response) != tt.status {
+				t.Errorf("%d endpoint: response = %v", tt.status, row.Response)
+			}
+			if (row.Error != "") != (tt.status != http.StatusOK) {
+				t.Errorf("%d endpoint: error = %q", tt.status, row.Error)
+			}
+			if tt.retried && (row.NextAttempt == nil || !row.NextAttempt.After(start)) {
+				t.Errorf("%d endpoint: next attempt = %v, want a later one", tt.status, row.NextAttempt)
+			}
+		}
+
+		// Only the pending delivery is due again, and not before its retry.
+		due, err := db.GetDueWebhookDeliveries(ctx, database.GetDueWebhookDeliveriesParams{
+			Now:   time.Now().Add(2 * time.Minute),
+			Limit: deliveryBatch,
+		})
+		if err != nil {
+			t.Fatalf("GetDueWebhookDeliveries: %v", err)
+		}
+		if len(due) != 1 || due[0].Url != urls[http.StatusInternalServerError] {
+			t.Errorf("due deliveries = %+v, want only the one to the 500 endpoint", due)
+		}
+	})
+}
+
+func TestWebhookAddChecksURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		allowPrivate bool
+		want         string
+	}{
+		{"ftp://hooks.example.com/gator", false, "must be an http or https URL"},
+		{"https:///gator", false, "must be an http or https URL"},
+		{"http://127.0.0.1:8080/gator", false, "non-public address 127.0.0.1"},
+		{"http://localhost/gator", false, "non-public address"},
+		{"http://[::1]/gator", false, "non-public address ::1"},
+		{"http://10.1.2.3/gator", false, "non-public address 10.1.2.3"},
+		{"http://192.168.0.10/gator", false, "non-public address"},
+		{"http://169.254.169.254/latest/meta-data", false, "non-public address 169.254.169.254"},
+		{"http://0.0.0.0/gator", false, "non-public address"},
+		{"http://127.0.0.1:8080/gator", true, ""},
+		{"https://203.0.113.10/gator", false, ""},
+	}
+	s := newTestState(t, memory.New())
+	register(t, s, "alice")
+	for _, tt := range tests {
+		args := []string{"webhook", "add", tt.url}
+		if tt.allowPrivate {
+			args = append(args, "--allow-private")
+		}
+		t.Run(strings.Join(args[2:], " "), func(t *testing.T) {
+			_, err := run(t, s, "", args...)
+			checkError(t, err, tt.want)
+		})
+	}
+}
@@ -10,8 +10,98 @@ import (
 const configFileName = ".gatorconfig.json"
 
 type Config struct {
-	DBURL           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DBURL           string          `json:"db_url"`
+	CurrentUserName string          `json:"current_user_name"`
+	Retention       RetentionConfig `json:"retention"`
+	// APIKey identifies the user when RequireAPIKey is set. The
+	// GATOR_API_KEY environment variable takes precedence.
+	APIKey        string     `json:"api_key,omitempty"`
+	RequireAPIKey bool       `json:"require_api_key,omitempty"`
+	SMTP          SMTPConfig `json:"smtp"`
+	Log           LogConfig  `json:"log"`
+}
+
+// LogConfig controls the diagnostic log written to stderr. The --log-level
+// and --log-format flags override it.
+type LogConfig struct {
+	// Level is debug, info (the default), warn or error.
+	Level string `json:"level,omitempty"`
+	// Format is text (the default) or json.
+	Format string `json:"format,omitempty"`
+}
+
+// SMTP connection security modes.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+// SMTPConfig is the mail server digests are sent through. The
+// GATOR_SMTP_PASSWORD environment variable takes precedence over Password.
+type SMTPConfig struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from,omitempty"`
+	// Security is starttls (the default), tls for implicit TLS, or none.
+	Security string `json:"security,omitempty"`
+}
+
+// WithDefaults returns the settings with the environment and defaults
+// applied.
+func (c SMTPConfig) WithDefaults() SMTPConfig {
+	if password := os.Getenv("GATOR_SMTP_PASSWORD"); password != "" {
+		c.Password = password
+	}
+	if c.Security == "" {
+		c.Security = SMTPStartTLS
+	}
+	if c.Port == 0 {
+		switch c.Security {
+		case SMTPTLS:
+			c.Port = 465
+		case SMTPNone:
+			c.Port = 25
+		default:
+			c.Port = 587
+		}
+	}
+	return c
+}
+
+// RetentionPolicy limits how many posts are kept for a feed. Zero values
+// mean no limit.
+type RetentionPolicy struct {
+	MaxAgeDays      int `json:"max_age_days,omitempty"`
+	MaxPostsPerFeed int `json:"max_posts_per_feed,omitempty"`
+}
+
+// RetentionConfig holds the global retention policy, per-feed overrides
+// keyed by feed URL, and options controlling when posts are pruned.
+type RetentionConfig struct {
+	RetentionPolicy
+	KeepUnread bool                       `json:"keep_unread,omitempty"`
+	PruneOnAgg bool                       `json:"prune_on_agg,omitempty"`
+	Feeds      map[string]RetentionPolicy `json:"feeds,omitempty"`
+}
+
+// PolicyFor returns the retention policy for a feed, using the global
+// policy for any limit the feed does not override.
+func (r RetentionConfig) PolicyFor(feedURL string) RetentionPolicy {
+	policy := r.RetentionPolicy
+	override, ok := r.Feeds[feedURL]
+	if !ok {
+		return policy
+	}
+	if override.MaxAgeDays != 0 {
+		policy.MaxAgeDays = override.MaxAgeDays
+	}
+	if override.MaxPostsPerFeed != 0 {
+		policy.MaxPostsPerFeed = override.MaxPostsPerFeed
+	}
+	return policy
 }
 
 func Read() (Config, error) {
@@ -40,6 +130,14 @@ func (cfg *Config) SetUser(name string) error {
 	return write(*cfg)
 }
 
+// SetLogin records the current user together with the API key that
+// authenticates them.
+func (cfg *Config) SetLogin(name, apiKey string) error {
+	cfg.CurrentUserName = name
+	cfg.APIKey = apiKey
+	return write(*cfg)
+}
+
 func getConfigFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -54,11 +152,18 @@ func write(cfg Config) error {
 		return err
 	}
 
-	file, err := os.Create(filePath)
+	// The file may hold an API key or SMTP password, so keep it private.
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
 		return fmt.Errorf("failed to create config file: %w", err)
 	}
 	defer file.Close()
+	if cfg.APIKey != "" || cfg.SMTP.Password != "" {
+		// Files created before API keys existed may be world-readable.
+		if err := file.Chmod(0o600); err != nil {
+			return fmt.Errorf("failed to restrict config file permissions: %w", err)
+		}
+	}
 
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "  ")
@@ -0,0 +1,76 @@
+// Package cursor encodes the opaque keyset pagination cursors used to page
+// through browsed posts.
+package cursor
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks the last post shown so the next page can resume after it
+// using keyset pagination.
+type Cursor struct {
+	Time time.Time
+	ID   uuid.UUID
+	Feed string
+}
+
+var ErrInvalid = errors.New("invalid cursor")
+
+// After returns the cursor that continues a page of posts sorted by sortBy
+// after last.
+func After(last database.BrowsePostsForUserRow, sortBy string) Cursor {
+	c := Cursor{ID: last.ID, Feed: last.FeedName, Time: last.CreatedAt}
+	if sortBy != "fetched" && last.PublishedAt.Valid {
+		c.Time = last.PublishedAt.Time
+	}
+	return c
+}
+
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + "," + c.ID.String() + "," + c.Feed
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func Decode(value string) (Cursor, error) {
+	var c Cursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalid
+	}
+
+	parts := strings.SplitN(string(raw), ",", 3)
+	if len(parts) != 3 {
+		return c, ErrInvalid
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return c, ErrInvalid
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return c, ErrInvalid
+	}
+
+	c.Time = time.Unix(0, nanos).UTC()
+	c.ID = id
+	c.Feed = parts[2]
+	return c, nil
+}
+
+// Apply sets the keyset parameters of params to continue after c.
+func (c Cursor) Apply(params *database.BrowsePostsForUserParams) {
+	params.AfterTime = sql.NullTime{Time: c.Time, Valid: true}
+	params.AfterID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	params.AfterFeed = sql.NullString{String: c.Feed, Valid: true}
+}
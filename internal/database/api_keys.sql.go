@@ -0,0 +1,200 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: api_keys.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (id, created_at, updated_at, user_id, name, prefix, key_hash, scope)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at, updated_at, user_id, name, prefix, key_hash, last_used_at, scope
+`
+
+type CreateAPIKeyParams struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	KeyHash   string    `json:"key_hash"`
+	Scope     string    `json:"scope"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, createAPIKey,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scope,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.LastUsedAt,
+		&i.Scope,
+	)
+	return i, err
+}
+
+const getUserByAPIKey = `-- name: GetUserByAPIKey :one
+SELECT users.id, users.created_at, users.updated_at, users.name, users.email, users.password_hash, users.failed_logins, users.locked_until, api_keys.scope FROM users
+INNER JOIN api_keys ON api_keys.user_id = users.id
+WHERE api_keys.key_hash = $1
+`
+
+type GetUserByAPIKeyRow struct {
+	User  User   `json:"user"`
+	Scope string `json:"scope"`
+}
+
+func (q *Queries) GetUserByAPIKey(ctx context.Context, keyHash string) (GetUserByAPIKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByAPIKey, keyHash)
+	var i GetUserByAPIKeyRow
+	err := row.Scan(
+		&i.User.ID,
+		&i.User.CreatedAt,
+		&i.User.UpdatedAt,
+		&i.User.Name,
+		&i.User.Email,
+		&i.User.PasswordHash,
+		&i.User.FailedLogins,
+		&i.User.LockedUntil,
+		&i.Scope,
+	)
+	return i, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT id, created_at, updated_at, user_id, name, prefix, key_hash, last_used_at, scope FROM api_keys
+ORDER BY created_at
+`
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.LastUsedAt,
+			&i.Scope,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIKeysForUser = `-- name: ListAPIKeysForUser :many
+SELECT id, created_at, updated_at, user_id, name, prefix, key_hash, last_used_at, scope FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListAPIKeysForUser(ctx context.Context, userID uuid.UUID) ([]ApiKey, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeysForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.LastUsedAt,
+			&i.Scope,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+DELETE FROM api_keys
+WHERE user_id = $1
+AND (
+    id::text = $2::text
+    OR prefix = $2::text
+)
+`
+
+type RevokeAPIKeyParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Ref    string    `json:"ref"`
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeAPIKey, arg.UserID, arg.Ref)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = $2
+WHERE key_hash = $1
+`
+
+type TouchAPIKeyParams struct {
+	KeyHash    string       `json:"key_hash"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+}
+
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey, arg.KeyHash, arg.LastUsedAt)
+	return err
+}
@@ -0,0 +1,63 @@
+// Package dbtest opens the Store backends that need no server, so tests can
+// check that handlers and queries behave the same on each of them.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"gator/internal/database"
+	"gator/internal/database/memory"
+	"gator/internal/database/sqlite"
+	"gator/internal/migrate"
+	sqliteschema "gator/sql/sqlite/schema"
+
+	_ "modernc.org/sqlite"
+)
+
+// Backend names a way of opening an empty, fully migrated Store.
+type Backend struct {
+	Name string
+	Open func(t testing.TB) database.Store
+}
+
+// Backends returns the in-memory store and SQLite.
+func Backends() []Backend {
+	return []Backend{
+		{Name: "memory", Open: func(testing.TB) database.Store { return memory.New() }},
+		{Name: "sqlite", Open: SQLite},
+	}
+}
+
+// Each runs fn as a subtest once per backend, each with a fresh store.
+func Each(t *testing.T, fn func(t *testing.T, db database.Store)) {
+	t.Helper()
+	for _, b := range Backends() {
+		t.Run(b.Name, func(t *testing.T) {
+			fn(t, b.Open(t))
+		})
+	}
+}
+
+// SQLite returns a store backed by a new SQLite file in a temporary
+// directory, opened with the same options as gator itself uses.
+func SQLite(t testing.TB) database.Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gator.db")
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, migrate.SQLite, sqliteschema.FS)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return sqlite.NewStore(db)
+}
@@ -0,0 +1,252 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: digests.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const deleteDigestSubscription = `-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDigestSubscription, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDigestPosts = `-- name: GetDigestPosts :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = $1
+AND ups.read_at IS NULL
+AND p.created_at > $2
+AND p.created_at <= $3
+ORDER BY p.created_at, p.id
+LIMIT $4
+`
+
+type GetDigestPostsParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	Limit  int32     `json:"limit"`
+}
+
+type GetDigestPostsRow struct {
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	FeedID      uuid.UUID      `json:"feed_id"`
+	Author      sql.NullString `json:"author"`
+	FeedName    string         `json:"feed_name"`
+	FeedUrl     string         `json:"feed_url"`
+}
+
+func (q *Queries) GetDigestPosts(ctx context.Context, arg GetDigestPostsParams) ([]GetDigestPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDigestPosts,
+		arg.UserID,
+		arg.Since,
+		arg.Until,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDigestPostsRow
+	for rows.Next() {
+		var i GetDigestPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDigestSubscription = `-- name: GetDigestSubscription :one
+SELECT user_id, created_at, updated_at, frequency, last_sent_at FROM digest_subscriptions
+WHERE user_id = $1
+`
+
+func (q *Queries) GetDigestSubscription(ctx context.Context, userID uuid.UUID) (DigestSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getDigestSubscription, userID)
+	var i DigestSubscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Frequency,
+		&i.LastSentAt,
+	)
+	return i, err
+}
+
+const getDigestSubscriptions = `-- name: GetDigestSubscriptions :many
+SELECT ds.user_id, ds.created_at, ds.updated_at, ds.frequency, ds.last_sent_at, u.name AS user_name, u.email
+FROM digest_subscriptions ds
+JOIN users u ON u.id = ds.user_id
+ORDER BY u.name
+`
+
+type GetDigestSubscriptionsRow struct {
+	UserID     uuid.UUID      `json:"user_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	Frequency  string         `json:"frequency"`
+	LastSentAt sql.NullTime   `json:"last_sent_at"`
+	UserName   string         `json:"user_name"`
+	Email      sql.NullString `json:"email"`
+}
+
+func (q *Queries) GetDigestSubscriptions(ctx context.Context) ([]GetDigestSubscriptionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDigestSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDigestSubscriptionsRow
+	for rows.Next() {
+		var i GetDigestSubscriptionsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Frequency,
+			&i.LastSentAt,
+			&i.UserName,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDigestSubscriptions = `-- name: ListDigestSubscriptions :many
+SELECT user_id, created_at, updated_at, frequency, last_sent_at FROM digest_subscriptions
+ORDER BY created_at
+`
+
+func (q *Queries) ListDigestSubscriptions(ctx context.Context) ([]DigestSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listDigestSubscriptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DigestSubscription
+	for rows.Next() {
+		var i DigestSubscription
+		if err := rows.Scan(
+			&i.UserID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Frequency,
+			&i.LastSentAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setDigestFrequency = `-- name: SetDigestFrequency :one
+INSERT INTO digest_subscriptions (user_id, created_at, updated_at, frequency)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET frequency = EXCLUDED.frequency,
+    updated_at = EXCLUDED.updated_at
+RETURNING user_id, created_at, updated_at, frequency, last_sent_at
+`
+
+type SetDigestFrequencyParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Frequency string    `json:"frequency"`
+}
+
+func (q *Queries) SetDigestFrequency(ctx context.Context, arg SetDigestFrequencyParams) (DigestSubscription, error) {
+	row := q.db.QueryRowContext(ctx, setDigestFrequency,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Frequency,
+	)
+	var i DigestSubscription
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Frequency,
+		&i.LastSentAt,
+	)
+	return i, err
+}
+
+const setDigestSent = `-- name: SetDigestSent :exec
+UPDATE digest_subscriptions
+SET last_sent_at = $2
+WHERE user_id = $1
+`
+
+type SetDigestSentParams struct {
+	UserID     uuid.UUID    `json:"user_id"`
+	LastSentAt sql.NullTime `json:"last_sent_at"`
+}
+
+func (q *Queries) SetDigestSent(ctx context.Context, arg SetDigestSentParams) error {
+	_, err := q.db.ExecContext(ctx, setDigestSent, arg.UserID, arg.LastSentAt)
+	return err
+}
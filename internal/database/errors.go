@@ -0,0 +1,41 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrUniqueViolation and ErrForeignKeyViolation are returned by
+	// implementations of Querier that do not sit on a SQL driver.
+	ErrUniqueViolation     = errors.New("unique constraint violated")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violated")
+)
+
+// SQLite extended result codes for constraint violations that mean a row
+// already exists.
+const (
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+// IsUniqueViolation reports whether err was caused by inserting a row that
+// conflicts with a unique constraint, on any supported backend.
+func IsUniqueViolation(err error) bool {
+	if errors.Is(err, ErrUniqueViolation) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqliteConstraintUnique || code == sqliteConstraintPrimaryKey
+	}
+	return false
+}
@@ -29,21 +29,21 @@ INNER JOIN users u ON u.id = iff.user_id
 `
 
 type CreateFeedFollowParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	UserName  string    `json:"user_name"`
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -76,8 +76,8 @@ AND feeds.url = $2
 `
 
 type DeleteFeedFollowParams struct {
-	UserID uuid.UUID
-	Url    string
+	UserID uuid.UUID `json:"user_id"`
+	Url    string    `json:"url"`
 }
 
 func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error {
@@ -85,6 +85,32 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 	return err
 }
 
+const getFeedFollowForUserByURL = `-- name: GetFeedFollowForUserByURL :one
+SELECT ff.id, ff.created_at, ff.updated_at, ff.user_id, ff.feed_id
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+WHERE ff.user_id = $1
+AND f.url = $2
+`
+
+type GetFeedFollowForUserByURLParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Url    string    `json:"url"`
+}
+
+func (q *Queries) GetFeedFollowForUserByURL(ctx context.Context, arg GetFeedFollowForUserByURLParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForUserByURL, arg.UserID, arg.Url)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+	)
+	return i, err
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
 ff.id,
@@ -93,7 +119,15 @@ ff.updated_at,
 ff.user_id,
 ff.feed_id,
 f.name AS feed_name,
-u.name AS user_name
+f.url AS feed_url,
+u.name AS user_name,
+(
+    SELECT COUNT(*)
+    FROM posts p
+    LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+    WHERE p.feed_id = ff.feed_id
+    AND ups.read_at IS NULL
+) AS unread_count
 FROM feed_follows ff
 INNER JOIN feeds f ON f.id = ff.feed_id
 INNER JOIN users u ON u.id = ff.user_id
@@ -101,13 +135,15 @@ WHERE ff.user_id = $1
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	FeedName  string
-	UserName  string
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	UserID      uuid.UUID `json:"user_id"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	FeedUrl     string    `json:"feed_url"`
+	UserName    string    `json:"user_name"`
+	UnreadCount int64     `json:"unread_count"`
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -126,7 +162,9 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UserID,
 			&i.FeedID,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
+			&i.UnreadCount,
 		); err != nil {
 			return nil, err
 		}
@@ -140,3 +178,46 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const listFeedFollows = `-- name: ListFeedFollows :many
+SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows
+ORDER BY created_at
+`
+
+func (q *Queries) ListFeedFollows(ctx context.Context) ([]FeedFollow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFollows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFollow
+	for rows.Next() {
+		var i FeedFollow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetFeedFollows = `-- name: ResetFeedFollows :exec
+DELETE FROM feed_follows
+`
+
+func (q *Queries) ResetFeedFollows(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetFeedFollows)
+	return err
+}
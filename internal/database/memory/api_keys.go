@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, arg database.CreateAPIKeyParams) (database.ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.ApiKey{}, foreignKeyViolation("fk_api_keys_user")
+	}
+	for _, k := range s.apiKeys {
+		if k.ID == arg.ID {
+			return database.ApiKey{}, uniqueViolation("api_keys_pkey")
+		}
+		if k.KeyHash == arg.KeyHash {
+			return database.ApiKey{}, uniqueViolation("api_keys_key_hash_key")
+		}
+	}
+
+	k := database.ApiKey{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+		Prefix:    arg.Prefix,
+		KeyHash:   arg.KeyHash,
+		Scope:     arg.Scope,
+	}
+	s.apiKeys = append(s.apiKeys, k)
+	return k, nil
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]database.ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.apiKeys, func(k database.ApiKey) time.Time { return k.CreatedAt }), nil
+}
+
+func (s *Store) ListAPIKeysForUser(ctx context.Context, userID uuid.UUID) ([]database.ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []database.ApiKey
+	for _, k := range byCreatedAt(s.apiKeys, func(k database.ApiKey) time.Time { return k.CreatedAt }) {
+		if k.UserID == userID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (s *Store) GetUserByAPIKey(ctx context.Context, keyHash string) (database.GetUserByAPIKeyRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.apiKeys {
+		if k.KeyHash == keyHash {
+			if u, ok := s.user(k.UserID); ok {
+				return database.GetUserByAPIKeyRow{User: u, Scope: k.Scope}, nil
+			}
+		}
+	}
+	return database.GetUserByAPIKeyRow{}, sql.ErrNoRows
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, arg database.TouchAPIKeyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apiKeys {
+		if s.apiKeys[i].KeyHash == arg.KeyHash {
+			s.apiKeys[i].LastUsedAt = arg.LastUsedAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, arg database.RevokeAPIKeyParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.apiKeys)
+	s.apiKeys = remove(s.apiKeys, func(k database.ApiKey) bool {
+		return k.UserID == arg.UserID && (k.ID.String() == arg.Ref || k.Prefix == arg.Ref)
+	})
+	return int64(before - len(s.apiKeys)), nil
+}
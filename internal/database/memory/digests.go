@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) SetDigestFrequency(ctx context.Context, arg database.SetDigestFrequencyParams) (database.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.digests {
+		// ON CONFLICT (user_id) DO UPDATE
+		if s.digests[i].UserID == arg.UserID {
+			s.digests[i].Frequency = arg.Frequency
+			s.digests[i].UpdatedAt = arg.UpdatedAt
+			return s.digests[i], nil
+		}
+	}
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.DigestSubscription{}, foreignKeyViolation("fk_digest_subscriptions_user")
+	}
+
+	d := database.DigestSubscription{
+		UserID:    arg.UserID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Frequency: arg.Frequency,
+	}
+	s.digests = append(s.digests, d)
+	return d, nil
+}
+
+func (s *Store) DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.digests)
+	s.digests = remove(s.digests, func(d database.DigestSubscription) bool { return d.UserID == userID })
+	return int64(n - len(s.digests)), nil
+}
+
+func (s *Store) GetDigestSubscription(ctx context.Context, userID uuid.UUID) (database.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.digests {
+		if d.UserID == userID {
+			return d, nil
+		}
+	}
+	return database.DigestSubscription{}, sql.ErrNoRows
+}
+
+func (s *Store) GetDigestSubscriptions(ctx context.Context) ([]database.GetDigestSubscriptionsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetDigestSubscriptionsRow
+	for _, d := range s.digests {
+		u, ok := s.user(d.UserID)
+		if !ok {
+			continue
+		}
+		rows = append(rows, database.GetDigestSubscriptionsRow{
+			UserID:     d.UserID,
+			CreatedAt:  d.CreatedAt,
+			UpdatedAt:  d.UpdatedAt,
+			Frequency:  d.Frequency,
+			LastSentAt: d.LastSentAt,
+			UserName:   u.Name,
+			Email:      u.Email,
+		})
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetDigestSubscriptionsRow) int {
+		return strings.Compare(a.UserName, b.UserName)
+	})
+	return rows, nil
+}
+
+func (s *Store) ListDigestSubscriptions(ctx context.Context) ([]database.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.digests, func(d database.DigestSubscription) time.Time { return d.CreatedAt }), nil
+}
+
+func (s *Store) SetDigestSent(ctx context.Context, arg database.SetDigestSentParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.digests {
+		if s.digests[i].UserID == arg.UserID {
+			s.digests[i].LastSentAt = arg.LastSentAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetDigestPosts(ctx context.Context, arg database.GetDigestPostsParams) ([]database.GetDigestPostsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetDigestPostsRow
+	for _, fp := range s.followedPosts(arg.UserID, sql.NullString{}) {
+		p := fp.post
+		if !s.isUnread(arg.UserID, p.ID) || !p.CreatedAt.After(arg.Since) || p.CreatedAt.After(arg.Until) {
+			continue
+		}
+		f, _ := s.feed(p.FeedID)
+		rows = append(rows, database.GetDigestPostsRow{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			Author:      p.Author,
+			FeedName:    f.Name,
+			FeedUrl:     f.Url,
+		})
+	}
+
+	slices.SortFunc(rows, func(a, b database.GetDigestPostsRow) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	if int(arg.Limit) < len(rows) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
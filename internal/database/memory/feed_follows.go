@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ff := range s.follows {
+		if ff.ID == arg.ID {
+			return database.CreateFeedFollowRow{}, uniqueViolation("feed_follows_pkey")
+		}
+	}
+	if _, ok := s.follow(arg.UserID, arg.FeedID); ok {
+		return database.CreateFeedFollowRow{}, uniqueViolation("unique_feed_follow")
+	}
+	u, ok := s.user(arg.UserID)
+	if !ok {
+		return database.CreateFeedFollowRow{}, foreignKeyViolation("fk_feed_follows_user")
+	}
+	f, ok := s.feed(arg.FeedID)
+	if !ok {
+		return database.CreateFeedFollowRow{}, foreignKeyViolation("fk_feed_follows_feed")
+	}
+
+	s.follows = append(s.follows, database.FeedFollow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+	})
+	return database.CreateFeedFollowRow{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		FeedID:    arg.FeedID,
+		FeedName:  f.Name,
+		UserName:  u.Name,
+	}, nil
+}
+
+func (s *Store) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFeedFollowsForUserRow
+	for _, ff := range s.follows {
+		if ff.UserID != userID {
+			continue
+		}
+		f, _ := s.feed(ff.FeedID)
+		u, _ := s.user(ff.UserID)
+
+		var unread int64
+		for _, p := range s.posts {
+			if p.FeedID == ff.FeedID && s.isUnread(ff.UserID, p.ID) {
+				unread++
+			}
+		}
+
+		rows = append(rows, database.GetFeedFollowsForUserRow{
+			ID:          ff.ID,
+			CreatedAt:   ff.CreatedAt,
+			UpdatedAt:   ff.UpdatedAt,
+			UserID:      ff.UserID,
+			FeedID:      ff.FeedID,
+			FeedName:    f.Name,
+			FeedUrl:     f.Url,
+			UserName:    u.Name,
+			UnreadCount: unread,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := selectIDs(s.follows, func(ff database.FeedFollow) (uuid.UUID, bool) {
+		f, _ := s.feed(ff.FeedID)
+		return ff.ID, ff.UserID == arg.UserID && f.Url == arg.Url
+	})
+	for _, id := range matches {
+		s.deleteFollow(id)
+	}
+	return nil
+}
+
+func (s *Store) GetFeedFollowForUserByURL(ctx context.Context, arg database.GetFeedFollowForUserByURLParams) (database.FeedFollow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ff := range s.follows {
+		f, _ := s.feed(ff.FeedID)
+		if ff.UserID == arg.UserID && f.Url == arg.Url {
+			return ff, nil
+		}
+	}
+	return database.FeedFollow{}, sql.ErrNoRows
+}
+
+func (s *Store) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.follows, func(ff database.FeedFollow) time.Time { return ff.CreatedAt }), nil
+}
+
+func (s *Store) ResetFeedFollows(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.follows) > 0 {
+		s.deleteFollow(s.follows[0].ID)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.feed(arg.ID); ok {
+		return database.Feed{}, uniqueViolation("feeds_pkey")
+	}
+	for _, f := range s.feeds {
+		if f.Url == arg.Url {
+			return database.Feed{}, uniqueViolation("feeds_url_key")
+		}
+	}
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.Feed{}, foreignKeyViolation("fk_user")
+	}
+
+	f := database.Feed{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Name:      arg.Name,
+		Url:       arg.Url,
+		UserID:    arg.UserID,
+	}
+	s.feeds = append(s.feeds, f)
+	return f, nil
+}
+
+func (s *Store) GetAllFeeds(ctx context.Context) ([]database.GetAllFeedsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetAllFeedsRow
+	for _, f := range s.feeds {
+		u, _ := s.user(f.UserID)
+		rows = append(rows, database.GetAllFeedsRow{
+			FeedName: f.Name,
+			Url:      f.Url,
+			UserName: u.Name,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.feeds {
+		if f.Url == url {
+			return f, nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) MarkedFeedFetched(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for i := range s.feeds {
+		if s.feeds[i].ID == id {
+			s.feeds[i].LastFetchedAt = sql.NullTime{Time: now, Valid: true}
+			s.feeds[i].UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.feeds) == 0 {
+		return database.Feed{}, sql.ErrNoRows
+	}
+
+	// ORDER BY last_fetched_at ASC NULLS FIRST
+	next := s.feeds[0]
+	for _, f := range s.feeds[1:] {
+		switch {
+		case !next.LastFetchedAt.Valid:
+		case !f.LastFetchedAt.Valid:
+			next = f
+		case f.LastFetchedAt.Time.Before(next.LastFetchedAt.Time):
+			next = f
+		}
+	}
+	return next, nil
+}
+
+func (s *Store) CountDueFeeds(ctx context.Context, before sql.NullTime) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, f := range s.feeds {
+		if !f.LastFetchedAt.Valid || (before.Valid && f.LastFetchedAt.Time.Before(before.Time)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) ListFeeds(ctx context.Context) ([]database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feeds := append([]database.Feed(nil), s.feeds...)
+	slices.SortStableFunc(feeds, func(a, b database.Feed) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return feeds, nil
+}
+
+func (s *Store) GetFeedFollowers(ctx context.Context, feedID uuid.UUID) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	follows := slices.Clone(s.follows)
+	slices.SortStableFunc(follows, func(a, b database.FeedFollow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var users []database.User
+	for _, ff := range follows {
+		if ff.FeedID != feedID {
+			continue
+		}
+		if u, ok := s.user(ff.UserID); ok {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) RenameFeed(ctx context.Context, arg database.RenameFeedParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.feeds {
+		if s.feeds[i].ID == arg.ID {
+			s.feeds[i].Name = arg.Name
+			s.feeds[i].UpdatedAt = time.Now()
+			return s.feeds[i], nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) UpdateFeedURL(ctx context.Context, arg database.UpdateFeedURLParams) (database.Feed, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.feeds {
+		if f.Url == arg.Url && f.ID != arg.ID {
+			return database.Feed{}, uniqueViolation("feeds_url_key")
+		}
+	}
+	for i := range s.feeds {
+		if s.feeds[i].ID == arg.ID {
+			s.feeds[i].Url = arg.Url
+			s.feeds[i].LastFetchedAt = sql.NullTime{}
+			s.feeds[i].UpdatedAt = time.Now()
+			return s.feeds[i], nil
+		}
+	}
+	return database.Feed{}, sql.ErrNoRows
+}
+
+func (s *Store) SetFeedOwner(ctx context.Context, arg database.SetFeedOwnerParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.user(arg.UserID); !ok {
+		return foreignKeyViolation("fk_user")
+	}
+	for i := range s.feeds {
+		if s.feeds[i].ID == arg.ID {
+			s.feeds[i].UserID = arg.UserID
+			s.feeds[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteFeed(id)
+	return nil
+}
+
+func (s *Store) DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := selectIDs(s.posts, func(p database.Post) (uuid.UUID, bool) { return p.ID, p.FeedID == feedID })
+	for _, id := range ids {
+		s.deletePost(id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (s *Store) ResetFeeds(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.feeds) > 0 {
+		s.deleteFeed(s.feeds[0].ID)
+	}
+	return nil
+}
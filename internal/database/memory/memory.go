@@ -0,0 +1,342 @@
+// Package memory implements database.Querier in memory. It mirrors the
+// PostgreSQL schema's unique constraints, cascading deletes and query
+// ordering so handlers can be exercised without a database server.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+type Store struct {
+	txMu       sync.Mutex
+	mu         sync.Mutex
+	users      []database.User
+	feeds      []database.Feed
+	follows    []database.FeedFollow
+	posts      []database.Post
+	states     []database.UserPostState
+	starred    []database.StarredPost
+	tags       []database.Tag
+	followTags []database.FeedFollowTag
+	apiKeys    []database.ApiKey
+	webhooks   []database.Webhook
+	deliveries []database.WebhookDelivery
+	digests    []database.DigestSubscription
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	return &Store{}
+}
+
+func uniqueViolation(constraint string) error {
+	return fmt.Errorf("duplicate key value violates %s: %w", constraint, database.ErrUniqueViolation)
+}
+
+func foreignKeyViolation(constraint string) error {
+	return fmt.Errorf("insert violates %s: %w", constraint, database.ErrForeignKeyViolation)
+}
+
+// remove returns items without the elements for which drop returns true.
+func remove[T any](items []T, drop func(T) bool) []T {
+	kept := items[:0]
+	for _, item := range items {
+		if !drop(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// The delete helpers below cascade exactly like the ON DELETE clauses in
+// sql/schema. Callers must hold s.mu.
+
+func (s *Store) deleteUser(id uuid.UUID) {
+	for _, feedID := range selectIDs(s.feeds, func(f database.Feed) (uuid.UUID, bool) { return f.ID, f.UserID == id }) {
+		s.deleteFeed(feedID)
+	}
+	for _, followID := range selectIDs(s.follows, func(ff database.FeedFollow) (uuid.UUID, bool) { return ff.ID, ff.UserID == id }) {
+		s.deleteFollow(followID)
+	}
+	for _, tagID := range selectIDs(s.tags, func(t database.Tag) (uuid.UUID, bool) { return t.ID, t.UserID == id }) {
+		s.deleteTag(tagID)
+	}
+	s.states = remove(s.states, func(st database.UserPostState) bool { return st.UserID == id })
+	s.starred = remove(s.starred, func(sp database.StarredPost) bool { return sp.UserID == id })
+	s.apiKeys = remove(s.apiKeys, func(k database.ApiKey) bool { return k.UserID == id })
+	for _, webhookID := range selectIDs(s.webhooks, func(w database.Webhook) (uuid.UUID, bool) { return w.ID, w.UserID == id }) {
+		s.deleteWebhook(webhookID)
+	}
+	s.digests = remove(s.digests, func(d database.DigestSubscription) bool { return d.UserID == id })
+	s.users = remove(s.users, func(u database.User) bool { return u.ID == id })
+}
+
+func (s *Store) deleteFeed(id uuid.UUID) {
+	for _, followID := range selectIDs(s.follows, func(ff database.FeedFollow) (uuid.UUID, bool) { return ff.ID, ff.FeedID == id }) {
+		s.deleteFollow(followID)
+	}
+	for _, postID := range selectIDs(s.posts, func(p database.Post) (uuid.UUID, bool) { return p.ID, p.FeedID == id }) {
+		s.deletePost(postID)
+	}
+	for _, webhookID := range selectIDs(s.webhooks, func(w database.Webhook) (uuid.UUID, bool) {
+		return w.ID, w.FeedID.Valid && w.FeedID.UUID == id
+	}) {
+		s.deleteWebhook(webhookID)
+	}
+	s.feeds = remove(s.feeds, func(f database.Feed) bool { return f.ID == id })
+}
+
+func (s *Store) deleteFollow(id uuid.UUID) {
+	s.followTags = remove(s.followTags, func(fft database.FeedFollowTag) bool { return fft.FeedFollowID == id })
+	s.follows = remove(s.follows, func(ff database.FeedFollow) bool { return ff.ID == id })
+}
+
+func (s *Store) deletePost(id uuid.UUID) {
+	s.states = remove(s.states, func(st database.UserPostState) bool { return st.PostID == id })
+	for i := range s.starred {
+		if s.starred[i].PostID.Valid && s.starred[i].PostID.UUID == id {
+			s.starred[i].PostID = uuid.NullUUID{}
+		}
+	}
+	for i := range s.deliveries {
+		if s.deliveries[i].PostID.Valid && s.deliveries[i].PostID.UUID == id {
+			s.deliveries[i].PostID = uuid.NullUUID{}
+		}
+	}
+	s.posts = remove(s.posts, func(p database.Post) bool { return p.ID == id })
+}
+
+func (s *Store) deleteWebhook(id uuid.UUID) {
+	s.deliveries = remove(s.deliveries, func(d database.WebhookDelivery) bool { return d.WebhookID == id })
+	s.webhooks = remove(s.webhooks, func(w database.Webhook) bool { return w.ID == id })
+}
+
+func (s *Store) deleteTag(id uuid.UUID) {
+	s.followTags = remove(s.followTags, func(fft database.FeedFollowTag) bool { return fft.TagID == id })
+	s.tags = remove(s.tags, func(t database.Tag) bool { return t.ID == id })
+}
+
+// selectIDs collects the IDs selected by match before any rows are deleted, so
+// cascades never modify a slice that is being iterated.
+func selectIDs[T any](items []T, match func(T) (uuid.UUID, bool)) []uuid.UUID {
+	var selected []uuid.UUID
+	for _, item := range items {
+		if id, ok := match(item); ok {
+			selected = append(selected, id)
+		}
+	}
+	return selected
+}
+
+// Lookups. Callers must hold s.mu.
+
+func (s *Store) user(id uuid.UUID) (database.User, bool) {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return database.User{}, false
+}
+
+func (s *Store) feed(id uuid.UUID) (database.Feed, bool) {
+	for _, f := range s.feeds {
+		if f.ID == id {
+			return f, true
+		}
+	}
+	return database.Feed{}, false
+}
+
+func (s *Store) follow(userID, feedID uuid.UUID) (database.FeedFollow, bool) {
+	for _, ff := range s.follows {
+		if ff.UserID == userID && ff.FeedID == feedID {
+			return ff, true
+		}
+	}
+	return database.FeedFollow{}, false
+}
+
+func (s *Store) post(id uuid.UUID) (database.Post, bool) {
+	for _, p := range s.posts {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return database.Post{}, false
+}
+
+func (s *Store) stateIndex(userID, postID uuid.UUID) int {
+	for i, st := range s.states {
+		if st.UserID == userID && st.PostID == postID {
+			return i
+		}
+	}
+	return -1
+}
+
+// isUnread reports whether userID has not read postID, matching the
+// LEFT JOIN user_post_state ... read_at IS NULL pattern in the queries.
+func (s *Store) isUnread(userID, postID uuid.UUID) bool {
+	i := s.stateIndex(userID, postID)
+	return i < 0 || !s.states[i].ReadAt.Valid
+}
+
+// followHasTag reports whether the follow is tagged with name.
+func (s *Store) followHasTag(followID uuid.UUID, name string) bool {
+	for _, fft := range s.followTags {
+		if fft.FeedFollowID != followID {
+			continue
+		}
+		for _, t := range s.tags {
+			if t.ID == fft.TagID && t.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// followedPosts returns every post in a feed followed by userID, paired with
+// the follow that matched, optionally limited to follows tagged with tag.
+func (s *Store) followedPosts(userID uuid.UUID, tag sql.NullString) []followedPost {
+	var matches []followedPost
+	for _, p := range s.posts {
+		ff, ok := s.follow(userID, p.FeedID)
+		if !ok {
+			continue
+		}
+		if tag.Valid && !s.followHasTag(ff.ID, tag.String) {
+			continue
+		}
+		matches = append(matches, followedPost{post: p, follow: ff})
+	}
+	return matches
+}
+
+type followedPost struct {
+	post   database.Post
+	follow database.FeedFollow
+}
+
+// sortTime is COALESCE(published_at, created_at).
+func sortTime(p database.Post) time.Time {
+	if p.PublishedAt.Valid {
+		return p.PublishedAt.Time
+	}
+	return p.CreatedAt
+}
+
+// compareIDs orders UUIDs the way PostgreSQL does, byte by byte.
+func compareIDs(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// byPublishedDesc orders posts by published_at DESC, which in PostgreSQL
+// places posts without a publication date first.
+func byPublishedDesc(a, b database.Post) int {
+	switch {
+	case a.PublishedAt.Valid == b.PublishedAt.Valid && !a.PublishedAt.Valid:
+		return 0
+	case !a.PublishedAt.Valid:
+		return -1
+	case !b.PublishedAt.Valid:
+		return 1
+	default:
+		return b.PublishedAt.Time.Compare(a.PublishedAt.Time)
+	}
+}
+
+func limitPosts(posts []database.Post, limit int32) []database.Post {
+	if limit >= 0 && int(limit) < len(posts) {
+		return posts[:limit]
+	}
+	return posts
+}
+
+// ExecTx runs fn against the store and restores the previous contents if it
+// returns an error. Transactions are serialized with each other but not
+// isolated from calls made outside a transaction.
+func (s *Store) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	saved := s.snapshot()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.restore(saved)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type snapshot struct {
+	users      []database.User
+	feeds      []database.Feed
+	follows    []database.FeedFollow
+	posts      []database.Post
+	states     []database.UserPostState
+	starred    []database.StarredPost
+	tags       []database.Tag
+	followTags []database.FeedFollowTag
+	apiKeys    []database.ApiKey
+	webhooks   []database.Webhook
+	deliveries []database.WebhookDelivery
+	digests    []database.DigestSubscription
+}
+
+func (s *Store) snapshot() snapshot {
+	return snapshot{
+		users:      slices.Clone(s.users),
+		feeds:      slices.Clone(s.feeds),
+		follows:    slices.Clone(s.follows),
+		posts:      slices.Clone(s.posts),
+		states:     slices.Clone(s.states),
+		starred:    slices.Clone(s.starred),
+		tags:       slices.Clone(s.tags),
+		followTags: slices.Clone(s.followTags),
+		apiKeys:    slices.Clone(s.apiKeys),
+		webhooks:   slices.Clone(s.webhooks),
+		deliveries: slices.Clone(s.deliveries),
+		digests:    slices.Clone(s.digests),
+	}
+}
+
+func (s *Store) restore(saved snapshot) {
+	s.users = saved.users
+	s.feeds = saved.feeds
+	s.follows = saved.follows
+	s.posts = saved.posts
+	s.states = saved.states
+	s.starred = saved.starred
+	s.tags = saved.tags
+	s.followTags = saved.followTags
+	s.apiKeys = saved.apiKeys
+	s.webhooks = saved.webhooks
+	s.deliveries = saved.deliveries
+	s.digests = saved.digests
+}
+
+// byCreatedAt returns a copy of items ordered by created_at.
+func byCreatedAt[T any](items []T, createdAt func(T) time.Time) []T {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b T) int {
+		return createdAt(a).Compare(createdAt(b))
+	})
+	return sorted
+}
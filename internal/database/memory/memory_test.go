@@ -0,0 +1,400 @@
+package memory_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/database/dbtest"
+
+	"github.com/google/uuid"
+)
+
+// The tests below run against every backend in dbtest and hold each one to
+// the same expectations, so a difference between the memory store and SQLite
+// fails the same assertion on one of them.
+
+var base = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	alice, bob database.User
+	feedA      database.Feed
+	feedB      database.Feed
+}
+
+func createUser(t *testing.T, db database.Store, name string) database.User {
+	t.Helper()
+	user, err := db.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: base,
+		UpdatedAt: base,
+		Name:      name,
+		Email:     sql.NullString{String: name + "@example.com", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", name, err)
+	}
+	return user
+}
+
+func createFeed(t *testing.T, db database.Store, user database.User, name, url string) database.Feed {
+	t.Helper()
+	feed, err := db.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: base,
+		UpdatedAt: base,
+		Name:      name,
+		Url:       url,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed(%s): %v", url, err)
+	}
+	return feed
+}
+
+func follow(t *testing.T, db database.Store, user database.User, feed database.Feed) database.CreateFeedFollowRow {
+	t.Helper()
+	ff, err := db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: base,
+		UpdatedAt: base,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedFollow: %v", err)
+	}
+	return ff
+}
+
+// createPost stores a post fetched at created and published at published,
+// or with no publication date if published is zero.
+func createPost(t *testing.T, db database.Store, feed database.Feed, url string, created, published time.Time) database.Post {
+	t.Helper()
+	post := database.Post{
+		ID:          uuid.New(),
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Title:       url,
+		Url:         url,
+		PublishedAt: sql.NullTime{Time: published, Valid: !published.IsZero()},
+		FeedID:      feed.ID,
+	}
+	n, err := db.CreatePost(context.Background(), database.CreatePostParams(post))
+	if err != nil {
+		t.Fatalf("CreatePost(%s): %v", url, err)
+	}
+	if n != 1 {
+		t.Fatalf("CreatePost(%s) stored %d rows, want 1", url, n)
+	}
+	return post
+}
+
+func newFixture(t *testing.T, db database.Store) fixture {
+	t.Helper()
+	f := fixture{alice: createUser(t, db, "alice"), bob: createUser(t, db, "bob")}
+	f.feedA = createFeed(t, db, f.alice, "Alpha", "https://a.example.com/rss")
+	f.feedB = createFeed(t, db, f.bob, "Beta", "https://b.example.com/rss")
+	return f
+}
+
+func TestUniqueViolations(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		f := newFixture(t, db)
+		follow(t, db, f.alice, f.feedA)
+
+		tests := []struct {
+			name string
+			do   func() error
+		}{
+			{"user email", func() error {
+				_, err := db.CreateUser(ctx, database.CreateUserParams{
+					ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Name: "carol",
+					Email: sql.NullString{String: "bob@example.com", Valid: true},
+				})
+				return err
+			}},
+			{"feed url", func() error {
+				_, err := db.CreateFeed(ctx, database.CreateFeedParams{
+					ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Name: "Again", Url: f.feedA.Url, UserID: f.bob.ID,
+				})
+				return err
+			}},
+			{"feed follow", func() error {
+				_, err := db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+					ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: f.alice.ID, FeedID: f.feedA.ID,
+				})
+				return err
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.do()
+				if !database.IsUniqueViolation(err) {
+					t.Errorf("got %v, want a unique violation", err)
+				}
+			})
+		}
+
+		t.Run("tag name returns the existing tag", func(t *testing.T) {
+			var ids []uuid.UUID
+			for range 2 {
+				tag, err := db.CreateTag(ctx, database.CreateTagParams{
+					ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: f.alice.ID, Name: "go",
+				})
+				if err != nil {
+					t.Fatalf("CreateTag: %v", err)
+				}
+				ids = append(ids, tag.ID)
+			}
+			if ids[0] != ids[1] {
+				t.Errorf("CreateTag returned %v then %v, want the same tag", ids[0], ids[1])
+			}
+		})
+
+		t.Run("post url is skipped", func(t *testing.T) {
+			createPost(t, db, f.feedA, "https://a.example.com/1", base, base)
+			n, err := db.CreatePost(ctx, database.CreatePostParams{
+				ID: uuid.New(), CreatedAt: base, UpdatedAt: base, Url: "https://a.example.com/1", FeedID: f.feedB.ID,
+			})
+			if err != nil || n != 0 {
+				t.Errorf("got (%d, %v), want (0, nil)", n, err)
+			}
+		})
+	})
+}
+
+func TestForeignKeyViolation(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		f := newFixture(t, db)
+		_, err := db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+			ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: f.alice.ID, FeedID: uuid.New(),
+		})
+		if err == nil {
+			t.Fatal("following a missing feed succeeded")
+		}
+		if errors.Is(err, database.ErrUniqueViolation) {
+			t.Errorf("got unique violation %v, want a foreign key violation", err)
+		}
+	})
+}
+
+func TestCascades(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		f := newFixture(t, db)
+		follow(t, db, f.alice, f.feedB)
+		follow(t, db, f.bob, f.feedB)
+		post := createPost(t, db, f.feedB, "https://b.example.com/1", base, base)
+		_, err := db.StarPost(ctx, database.StarPostParams{ID: uuid.New(), UserID: f.alice.ID, PostID: post.ID})
+		if err != nil {
+			t.Fatalf("StarPost: %v", err)
+		}
+
+		// Deleting bob deletes his feed, its follows and posts, but alice's
+		// star survives with the post unlinked.
+		if err := db.DeleteUser(ctx, f.bob.ID); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		if _, err := db.GetFeedByURL(ctx, f.feedB.Url); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetFeedByURL after deleting owner: got %v, want sql.ErrNoRows", err)
+		}
+		if _, err := db.GetPostByURL(ctx, post.Url); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetPostByURL after deleting feed: got %v, want sql.ErrNoRows", err)
+		}
+		follows, err := db.GetFeedFollowsForUser(ctx, f.alice.ID)
+		if err != nil {
+			t.Fatalf("GetFeedFollowsForUser: %v", err)
+		}
+		if len(follows) != 0 {
+			t.Errorf("alice still follows %d feeds, want 0", len(follows))
+		}
+		starred, err := db.GetStarredPostsForUser(ctx, database.GetStarredPostsForUserParams{UserID: f.alice.ID, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetStarredPostsForUser: %v", err)
+		}
+		if len(starred) != 1 || starred[0].PostID.Valid || starred[0].Url != post.Url {
+			t.Errorf("starred posts = %+v, want one unlinked copy of %s", starred, post.Url)
+		}
+	})
+}
+
+func TestBrowseOrdering(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		f := newFixture(t, db)
+		follow(t, db, f.alice, f.feedA)
+		follow(t, db, f.alice, f.feedB)
+
+		hour := time.Hour
+		createPost(t, db, f.feedB, "b-old", base.Add(3*hour), base.Add(-2*hour))
+		createPost(t, db, f.feedA, "a-undated", base.Add(1*hour), time.Time{})
+		createPost(t, db, f.feedA, "a-new", base.Add(2*hour), base)
+		createPost(t, db, f.feedB, "b-mid", base.Add(4*hour), base.Add(-1*hour))
+
+		tests := []struct {
+			sort string
+			want []string
+		}{
+			{"published", []string{"a-undated", "a-new", "b-mid", "b-old"}},
+			{"fetched", []string{"b-mid", "b-old", "a-new", "a-undated"}},
+			{"feed", []string{"a-undated", "a-new", "b-mid", "b-old"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.sort, func(t *testing.T) {
+				posts, err := db.BrowsePostsForUser(ctx, database.BrowsePostsForUserParams{
+					UserID: f.alice.ID,
+					Sort:   tt.sort,
+					Limit:  10,
+				})
+				if err != nil {
+					t.Fatalf("BrowsePostsForUser: %v", err)
+				}
+				var got []string
+				for _, p := range posts {
+					got = append(got, p.Url)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestNextFeedToFetch(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		newFixture(t, db)
+
+		// Never-fetched feeds come first, then the one fetched longest ago.
+		var order []string
+		for range 3 {
+			feed, err := db.GetNextFeedToFetch(ctx)
+			if err != nil {
+				t.Fatalf("GetNextFeedToFetch: %v", err)
+			}
+			order = append(order, feed.Name)
+			if err := db.MarkedFeedFetched(ctx, feed.ID); err != nil {
+				t.Fatalf("MarkedFeedFetched: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if order[0] == order[1] || order[2] != order[0] {
+			t.Errorf("fetch order = %v, want each feed once, then the first again", order)
+		}
+	})
+}
+
+func TestRecordFailedLoginConcurrently(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		user := createUser(t, db, "alice")
+
+		const attempts = 20
+		counts := make(chan int32, attempts)
+		var wg sync.WaitGroup
+		for range attempts {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, err := db.RecordFailedLogin(ctx, user.ID)
+				if err != nil {
+					t.Errorf("RecordFailedLogin: %v", err)
+				}
+				counts <- n
+			}()
+		}
+		wg.Wait()
+		close(counts)
+
+		// Every attempt sees its own count.
+		var got []int
+		for n := range counts {
+			got = append(got, int(n))
+		}
+		slices.Sort(got)
+		for i, n := range got {
+			if n != i+1 {
+				t.Fatalf("counts = %v, want 1 to %d", got, attempts)
+			}
+		}
+	})
+}
+
+func createWebhook(t *testing.T, db database.Store, user database.User, url string, feed *database.Feed, tag string) {
+	t.Helper()
+	arg := database.CreateWebhookParams{
+		ID:        uuid.New(),
+		CreatedAt: base,
+		UpdatedAt: base,
+		UserID:    user.ID,
+		Url:       url,
+		Secret:    "whsec_test",
+		Format:    "json",
+		Tag:       sql.NullString{String: tag, Valid: tag != ""},
+	}
+	if feed != nil {
+		arg.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+	if _, err := db.CreateWebhook(context.Background(), arg); err != nil {
+		t.Fatalf("CreateWebhook(%s): %v", url, err)
+	}
+}
+
+func TestWebhooksForFeed(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db database.Store) {
+		ctx := context.Background()
+		f := newFixture(t, db)
+		follow(t, db, f.alice, f.feedA)
+		ff := follow(t, db, f.alice, f.feedB)
+		tag, err := db.CreateTag(ctx, database.CreateTagParams{
+			ID: uuid.New(), CreatedAt: base, UpdatedAt: base, UserID: f.alice.ID, Name: "news",
+		})
+		if err != nil {
+			t.Fatalf("CreateTag: %v", err)
+		}
+		if err := db.AddFeedFollowTag(ctx, database.AddFeedFollowTagParams{FeedFollowID: ff.ID, TagID: tag.ID}); err != nil {
+			t.Fatalf("AddFeedFollowTag: %v", err)
+		}
+
+		createWebhook(t, db, f.alice, "https://hooks.example.com/all", nil, "")
+		createWebhook(t, db, f.alice, "https://hooks.example.com/a", &f.feedA, "")
+		createWebhook(t, db, f.alice, "https://hooks.example.com/news", nil, "news")
+		createWebhook(t, db, f.alice, "https://hooks.example.com/b-news", &f.feedB, "news")
+		// bob owns feed A but doesn't follow it.
+		createWebhook(t, db, f.bob, "https://hooks.example.com/bob", nil, "")
+
+		tests := []struct {
+			feed database.Feed
+			want []string
+		}{
+			{f.feedA, []string{"https://hooks.example.com/all", "https://hooks.example.com/a"}},
+			{f.feedB, []string{"https://hooks.example.com/all", "https://hooks.example.com/news", "https://hooks.example.com/b-news"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.feed.Name, func(t *testing.T) {
+				webhooks, err := db.GetWebhooksForFeed(ctx, tt.feed.ID)
+				if err != nil {
+					t.Fatalf("GetWebhooksForFeed: %v", err)
+				}
+				var got []string
+				for _, w := range webhooks {
+					got = append(got, w.Url)
+				}
+				slices.Sort(got)
+				want := slices.Sorted(slices.Values(tt.want))
+				if !slices.Equal(got, want) {
+					t.Errorf("got %v, want %v", got, want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreatePost(ctx context.Context, arg database.CreatePostParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.posts {
+		// ON CONFLICT (url) DO NOTHING
+		if p.Url == arg.Url {
+			return 0, nil
+		}
+		if p.ID == arg.ID {
+			return 0, uniqueViolation("posts_pkey")
+		}
+	}
+	if _, ok := s.feed(arg.FeedID); !ok {
+		return 0, foreignKeyViolation("fk_post_feed")
+	}
+
+	s.posts = append(s.posts, database.Post{
+		ID:          arg.ID,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+		Title:       arg.Title,
+		Url:         arg.Url,
+		Description: arg.Description,
+		PublishedAt: arg.PublishedAt,
+		FeedID:      arg.FeedID,
+		Author:      arg.Author,
+	})
+	return 1, nil
+}
+
+func (s *Store) GetPostsForUSer(ctx context.Context, arg database.GetPostsForUSerParams) ([]database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var posts []database.Post
+	for _, fp := range s.followedPosts(arg.UserID, arg.Tag) {
+		posts = append(posts, fp.post)
+	}
+	slices.SortStableFunc(posts, byPublishedDesc)
+	return limitPosts(posts, arg.Limit), nil
+}
+
+func (s *Store) GetPostByID(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.post(id); ok {
+		return p, nil
+	}
+	return database.Post{}, sql.ErrNoRows
+}
+
+func (s *Store) GetPostByURL(ctx context.Context, url string) (database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.posts {
+		if p.Url == url {
+			return p, nil
+		}
+	}
+	return database.Post{}, sql.ErrNoRows
+}
+
+func (s *Store) BrowsePostsForUser(ctx context.Context, arg database.BrowsePostsForUserParams) ([]database.BrowsePostsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// keyTime is the time column the chosen sort orders by.
+	keyTime := sortTime
+	if arg.Sort == "fetched" {
+		keyTime = func(p database.Post) time.Time { return p.CreatedAt }
+	}
+
+	var rows []database.BrowsePostsForUserRow
+	for _, fp := range s.followedPosts(arg.UserID, arg.Tag) {
+		p := fp.post
+		f, _ := s.feed(p.FeedID)
+
+		var readAt sql.NullTime
+		if i := s.stateIndex(arg.UserID, p.ID); i >= 0 {
+			readAt = s.states[i].ReadAt
+		}
+
+		if arg.UnreadOnly && readAt.Valid {
+			continue
+		}
+		if arg.FeedUrl.Valid && f.Url != arg.FeedUrl.String {
+			continue
+		}
+		if arg.Author.Valid && !(p.Author.Valid && strings.Contains(strings.ToLower(p.Author.String), strings.ToLower(arg.Author.String))) {
+			continue
+		}
+		if arg.Since.Valid && sortTime(p).Before(arg.Since.Time) {
+			continue
+		}
+		if arg.Until.Valid && !sortTime(p).Before(arg.Until.Time) {
+			continue
+		}
+		if arg.AfterID.Valid {
+			afterID, afterTime := arg.AfterID.UUID, arg.AfterTime.Time
+			switch arg.Sort {
+			case "feed":
+				if f.Name < arg.AfterFeed.String {
+					continue
+				}
+				if f.Name == arg.AfterFeed.String && !keyBefore(keyTime(p), p.ID, afterTime, afterID) {
+					continue
+				}
+			case "fetched", "published":
+				if !keyBefore(keyTime(p), p.ID, afterTime, afterID) {
+					continue
+				}
+			}
+		}
+
+		rows = append(rows, database.BrowsePostsForUserRow{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			Author:      p.Author,
+			FeedName:    f.Name,
+			ReadAt:      readAt,
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b database.BrowsePostsForUserRow) int {
+		if arg.Sort == "feed" {
+			if c := strings.Compare(a.FeedName, b.FeedName); c != 0 {
+				return c
+			}
+		}
+		ta := keyTime(database.Post{CreatedAt: a.CreatedAt, PublishedAt: a.PublishedAt})
+		tb := keyTime(database.Post{CreatedAt: b.CreatedAt, PublishedAt: b.PublishedAt})
+		if c := tb.Compare(ta); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+
+	if int(arg.Offset) >= len(rows) {
+		return nil, nil
+	}
+	rows = rows[arg.Offset:]
+	if int(arg.Limit) < len(rows) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+// keyBefore reports whether (t, id) sorts after the cursor (afterTime,
+// afterID) in descending order, i.e. (t, id) < (afterTime, afterID).
+func keyBefore(t time.Time, id uuid.UUID, afterTime time.Time, afterID uuid.UUID) bool {
+	if !t.Equal(afterTime) {
+		return t.Before(afterTime)
+	}
+	return compareIDs(id, afterID) < 0
+}
+
+func (s *Store) GetPostsForUserAfter(ctx context.Context, arg database.GetPostsForUserAfterParams) ([]database.GetPostsForUserAfterRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetPostsForUserAfterRow
+	for _, fp := range s.followedPosts(arg.UserID, sql.NullString{}) {
+		p := fp.post
+		// (created_at, id) > (after_time, after_id)
+		if p.CreatedAt.Before(arg.AfterTime) || p.CreatedAt.Equal(arg.AfterTime) && compareIDs(p.ID, arg.AfterID) <= 0 {
+			continue
+		}
+		f, _ := s.feed(p.FeedID)
+		rows = append(rows, database.GetPostsForUserAfterRow{
+			ID:          p.ID,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			Title:       p.Title,
+			Url:         p.Url,
+			Description: p.Description,
+			PublishedAt: p.PublishedAt,
+			FeedID:      p.FeedID,
+			Author:      p.Author,
+			FeedName:    f.Name,
+			FeedUrl:     f.Url,
+		})
+	}
+
+	slices.SortStableFunc(rows, func(a, b database.GetPostsForUserAfterRow) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	if int(arg.Limit) < len(rows) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+func (s *Store) ListPosts(ctx context.Context) ([]database.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.posts, func(p database.Post) time.Time { return p.CreatedAt }), nil
+}
+
+func (s *Store) ResetPosts(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.posts) > 0 {
+		s.deletePost(s.posts[0].ID)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) GetPrunablePosts(ctx context.Context, arg database.GetPrunablePostsParams) ([]database.GetPrunablePostsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var posts []database.Post
+	for _, p := range s.posts {
+		if p.FeedID == arg.FeedID {
+			posts = append(posts, p)
+		}
+	}
+	slices.SortStableFunc(posts, func(a, b database.Post) int {
+		if c := sortTime(b).Compare(sortTime(a)); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+
+	var rows []database.GetPrunablePostsRow
+	for i, p := range posts {
+		position := int64(i + 1)
+		tooOld := arg.OlderThan.Valid && sortTime(p).Before(arg.OlderThan.Time)
+		tooMany := arg.MaxPosts.Valid && position > arg.MaxPosts.Int64
+		if !tooOld && !tooMany {
+			continue
+		}
+		if slices.ContainsFunc(s.starred, func(sp database.StarredPost) bool {
+			return sp.PostID.Valid && sp.PostID.UUID == p.ID
+		}) {
+			continue
+		}
+		if arg.KeepUnread && slices.ContainsFunc(s.follows, func(ff database.FeedFollow) bool {
+			return ff.FeedID == p.FeedID && s.isUnread(ff.UserID, p.ID)
+		}) {
+			continue
+		}
+		rows = append(rows, database.GetPrunablePostsRow{
+			ID:    p.ID,
+			Title: p.Title,
+			Url:   p.Url,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, id := range ids {
+		if _, ok := s.post(id); ok {
+			s.deletePost(id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) StarPost(ctx context.Context, arg database.StarPostParams) (database.StarredPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.post(arg.PostID)
+	if !ok {
+		return database.StarredPost{}, sql.ErrNoRows
+	}
+	f, _ := s.feed(p.FeedID)
+	now := time.Now()
+
+	for i, sp := range s.starred {
+		if sp.UserID == arg.UserID && sp.Url == p.Url {
+			s.starred[i].Note = arg.Note
+			s.starred[i].UpdatedAt = now
+			return s.starred[i], nil
+		}
+	}
+	for _, sp := range s.starred {
+		if sp.ID == arg.ID {
+			return database.StarredPost{}, uniqueViolation("starred_posts_pkey")
+		}
+	}
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.StarredPost{}, foreignKeyViolation("fk_starred_posts_user")
+	}
+
+	sp := database.StarredPost{
+		ID:          arg.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		UserID:      arg.UserID,
+		PostID:      uuid.NullUUID{UUID: p.ID, Valid: true},
+		FeedName:    f.Name,
+		Title:       p.Title,
+		Url:         p.Url,
+		Description: p.Description,
+		PublishedAt: p.PublishedAt,
+		Note:        arg.Note,
+	}
+	s.starred = append(s.starred, sp)
+	return sp, nil
+}
+
+func (s *Store) UnstarPost(ctx context.Context, arg database.UnstarPostParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.starred)
+	s.starred = remove(s.starred, func(sp database.StarredPost) bool {
+		if sp.UserID != arg.UserID {
+			return false
+		}
+		return sp.ID.String() == arg.Ref ||
+			(sp.PostID.Valid && sp.PostID.UUID.String() == arg.Ref) ||
+			sp.Url == arg.Ref
+	})
+	return int64(before - len(s.starred)), nil
+}
+
+func (s *Store) GetStarredPostsForUser(ctx context.Context, arg database.GetStarredPostsForUserParams) ([]database.StarredPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var starred []database.StarredPost
+	for _, sp := range s.starred {
+		if sp.UserID == arg.UserID {
+			starred = append(starred, sp)
+		}
+	}
+	slices.SortStableFunc(starred, func(a, b database.StarredPost) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+
+	if int(arg.Offset) >= len(starred) {
+		return nil, nil
+	}
+	starred = starred[arg.Offset:]
+	if int(arg.Limit) < len(starred) {
+		starred = starred[:arg.Limit]
+	}
+	return starred, nil
+}
+
+func (s *Store) CountStarredPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, sp := range s.starred {
+		if sp.UserID == userID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) ListStarredPosts(ctx context.Context) ([]database.StarredPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.starred, func(sp database.StarredPost) time.Time { return sp.CreatedAt }), nil
+}
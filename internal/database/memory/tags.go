@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateTag(ctx context.Context, arg database.CreateTagParams) (database.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tags {
+		if t.UserID == arg.UserID && t.Name == arg.Name {
+			return t, nil
+		}
+	}
+	for _, t := range s.tags {
+		if t.ID == arg.ID {
+			return database.Tag{}, uniqueViolation("tags_pkey")
+		}
+	}
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.Tag{}, foreignKeyViolation("fk_tags_user")
+	}
+
+	t := database.Tag{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+	}
+	s.tags = append(s.tags, t)
+	return t, nil
+}
+
+func (s *Store) AddFeedFollowTag(ctx context.Context, arg database.AddFeedFollowTagParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, fft := range s.followTags {
+		if fft.FeedFollowID == arg.FeedFollowID && fft.TagID == arg.TagID {
+			return nil
+		}
+	}
+	if !slices.ContainsFunc(s.follows, func(ff database.FeedFollow) bool { return ff.ID == arg.FeedFollowID }) {
+		return foreignKeyViolation("fk_feed_follow_tags_follow")
+	}
+	if !slices.ContainsFunc(s.tags, func(t database.Tag) bool { return t.ID == arg.TagID }) {
+		return foreignKeyViolation("fk_feed_follow_tags_tag")
+	}
+
+	s.followTags = append(s.followTags, database.FeedFollowTag{
+		FeedFollowID: arg.FeedFollowID,
+		TagID:        arg.TagID,
+		CreatedAt:    time.Now(),
+	})
+	return nil
+}
+
+func (s *Store) RemoveFeedFollowTag(ctx context.Context, arg database.RemoveFeedFollowTagParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := len(s.followTags)
+	s.followTags = remove(s.followTags, func(fft database.FeedFollowTag) bool {
+		if fft.FeedFollowID != arg.FeedFollowID {
+			return false
+		}
+		return slices.ContainsFunc(s.tags, func(t database.Tag) bool {
+			return t.ID == fft.TagID && t.Name == arg.Name
+		})
+	})
+	return int64(before - len(s.followTags)), nil
+}
+
+func (s *Store) DeleteUnusedTags(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tags = remove(s.tags, func(t database.Tag) bool {
+		return t.UserID == userID && !slices.ContainsFunc(s.followTags, func(fft database.FeedFollowTag) bool {
+			return fft.TagID == t.ID
+		})
+	})
+	return nil
+}
+
+func (s *Store) GetFeedFollowTagsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowTagsForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetFeedFollowTagsForUserRow
+	for _, fft := range s.followTags {
+		for _, t := range s.tags {
+			if t.ID == fft.TagID && t.UserID == userID {
+				rows = append(rows, database.GetFeedFollowTagsForUserRow{
+					FeedFollowID: fft.FeedFollowID,
+					TagName:      t.Name,
+				})
+			}
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetFeedFollowTagsForUserRow) int {
+		return strings.Compare(a.TagName, b.TagName)
+	})
+	return rows, nil
+}
+
+func (s *Store) ListTags(ctx context.Context) ([]database.Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.tags, func(t database.Tag) time.Time { return t.CreatedAt }), nil
+}
+
+func (s *Store) ListFeedFollowTags(ctx context.Context) ([]database.FeedFollowTag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.followTags), nil
+}
+
+func (s *Store) ResetTags(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.tags) > 0 {
+		s.deleteTag(s.tags[0].ID)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.user(arg.UserID); !ok {
+		return foreignKeyViolation("fk_user_post_state_user")
+	}
+	if _, ok := s.post(arg.PostID); !ok {
+		return foreignKeyViolation("fk_user_post_state_post")
+	}
+
+	s.markRead(arg.UserID, arg.PostID, time.Now())
+	return nil
+}
+
+func (s *Store) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.stateIndex(arg.UserID, arg.PostID); i >= 0 {
+		s.states[i].ReadAt = sql.NullTime{}
+		s.states[i].UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (s *Store) MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, fp := range s.followedPosts(arg.UserID, sql.NullString{}) {
+		p := fp.post
+		f, _ := s.feed(p.FeedID)
+		if arg.FeedUrl.Valid && f.Url != arg.FeedUrl.String {
+			continue
+		}
+		if arg.Before.Valid && !sortTime(p).Before(arg.Before.Time) {
+			continue
+		}
+		// ON CONFLICT ... DO UPDATE ... WHERE read_at IS NULL only counts
+		// rows that were actually unread.
+		if s.isUnread(arg.UserID, p.ID) {
+			s.markRead(arg.UserID, p.ID, now)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// markRead upserts the read state for a post. Callers must hold s.mu.
+func (s *Store) markRead(userID, postID uuid.UUID, now time.Time) {
+	if i := s.stateIndex(userID, postID); i >= 0 {
+		s.states[i].ReadAt = sql.NullTime{Time: now, Valid: true}
+		s.states[i].UpdatedAt = now
+		return
+	}
+	s.states = append(s.states, database.UserPostState{
+		UserID:    userID,
+		PostID:    postID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ReadAt:    sql.NullTime{Time: now, Valid: true},
+	})
+}
+
+func (s *Store) ListUserPostStates(ctx context.Context) ([]database.UserPostState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.states), nil
+}
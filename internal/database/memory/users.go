@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.user(arg.ID); ok {
+		return database.User{}, uniqueViolation("users_pkey")
+	}
+	for _, u := range s.users {
+		if arg.Email.Valid && u.Email == arg.Email {
+			return database.User{}, uniqueViolation("users_email_key")
+		}
+	}
+
+	u := database.User{
+		ID:           arg.ID,
+		CreatedAt:    arg.CreatedAt,
+		UpdatedAt:    arg.UpdatedAt,
+		Name:         arg.Name,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+	}
+	s.users = append(s.users, u)
+	return u, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, name string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Name == name {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email sql.NullString) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if email.Valid && u.Email == email {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *Store) GetUsers(ctx context.Context) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]database.User(nil), s.users...), nil
+}
+
+func (s *Store) ResetUsers(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.users) > 0 {
+		s.deleteUser(s.users[0].ID)
+	}
+	return nil
+}
+
+func (s *Store) GetUserStats(ctx context.Context) ([]database.GetUserStatsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetUserStatsRow
+	for _, u := range s.users {
+		row := database.GetUserStatsRow{
+			ID:           u.ID,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    u.UpdatedAt,
+			Name:         u.Name,
+			Email:        u.Email,
+			PasswordHash: u.PasswordHash,
+			FailedLogins: u.FailedLogins,
+			LockedUntil:  u.LockedUntil,
+			LastActiveAt: u.UpdatedAt,
+		}
+		active := func(t time.Time) {
+			if t.After(row.LastActiveAt) {
+				row.LastActiveAt = t
+			}
+		}
+		for _, f := range s.feeds {
+			if f.UserID == u.ID {
+				row.FeedCount++
+				active(f.CreatedAt)
+			}
+		}
+		for _, ff := range s.follows {
+			if ff.UserID == u.ID {
+				row.FollowCount++
+				active(ff.CreatedAt)
+			}
+		}
+		for _, st := range s.states {
+			if st.UserID == u.ID && st.ReadAt.Valid {
+				active(st.ReadAt.Time)
+			}
+		}
+		for _, sp := range s.starred {
+			if sp.UserID == u.ID {
+				active(sp.CreatedAt)
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetUserStatsRow) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return rows, nil
+}
+
+func (s *Store) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].Name = arg.Name
+			s.users[i].UpdatedAt = time.Now()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteUser(id)
+	return nil
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].PasswordHash = arg.PasswordHash
+			s.users[i].FailedLogins = 0
+			s.users[i].LockedUntil = sql.NullTime{}
+			s.users[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) SetUserEmail(ctx context.Context, arg database.SetUserEmailParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if arg.Email.Valid && u.Email == arg.Email && u.ID != arg.ID {
+			return uniqueViolation("users_email_key")
+		}
+	}
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].Email = arg.Email
+			s.users[i].UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Store) SetLoginFailures(ctx context.Context, arg database.SetLoginFailuresParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].FailedLogins = arg.FailedLogins
+			s.users[i].LockedUntil = arg.LockedUntil
+		}
+	}
+	return nil
+}
+
+func (s *Store) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.users {
+		if s.users[i].ID == id {
+			s.users[i].FailedLogins++
+			return s.users[i].FailedLogins, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
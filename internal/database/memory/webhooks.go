@@ -0,0 +1,243 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.user(arg.UserID); !ok {
+		return database.Webhook{}, foreignKeyViolation("fk_webhooks_user")
+	}
+	if arg.FeedID.Valid {
+		if _, ok := s.feed(arg.FeedID.UUID); !ok {
+			return database.Webhook{}, foreignKeyViolation("fk_webhooks_feed")
+		}
+	}
+	for _, w := range s.webhooks {
+		if w.ID == arg.ID {
+			return database.Webhook{}, uniqueViolation("webhooks_pkey")
+		}
+	}
+
+	w := database.Webhook(arg)
+	s.webhooks = append(s.webhooks, w)
+	return w, nil
+}
+
+func (s *Store) GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]database.GetWebhooksForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetWebhooksForUserRow
+	for _, w := range byCreatedAt(s.webhooks, func(w database.Webhook) time.Time { return w.CreatedAt }) {
+		if w.UserID != userID {
+			continue
+		}
+		row := database.GetWebhooksForUserRow{
+			ID:        w.ID,
+			CreatedAt: w.CreatedAt,
+			UpdatedAt: w.UpdatedAt,
+			UserID:    w.UserID,
+			Url:       w.Url,
+			Secret:    w.Secret,
+			Format:    w.Format,
+			FeedID:    w.FeedID,
+			Tag:       w.Tag,
+			Keyword:   w.Keyword,
+		}
+		if w.FeedID.Valid {
+			if f, ok := s.feed(w.FeedID.UUID); ok {
+				row.FeedUrl.String, row.FeedUrl.Valid = f.Url, true
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return byCreatedAt(s.webhooks, func(w database.Webhook) time.Time { return w.CreatedAt }), nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := selectIDs(s.webhooks, func(w database.Webhook) (uuid.UUID, bool) {
+		return w.ID, w.UserID == arg.UserID && w.ID.String() == arg.Ref
+	})
+	for _, id := range ids {
+		s.deleteWebhook(id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (s *Store) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var webhooks []database.Webhook
+	for _, w := range byCreatedAt(s.webhooks, func(w database.Webhook) time.Time { return w.CreatedAt }) {
+		ff, ok := s.follow(w.UserID, feedID)
+		if !ok {
+			continue
+		}
+		if w.FeedID.Valid && w.FeedID.UUID != feedID {
+			continue
+		}
+		if w.Tag.Valid && !s.followHasTag(ff.ID, w.Tag.String) {
+			continue
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.deliveries {
+		// ON CONFLICT (webhook_id, post_id) DO NOTHING
+		if d.WebhookID == arg.WebhookID && arg.PostID.Valid && d.PostID == arg.PostID {
+			return 0, nil
+		}
+		if d.ID == arg.ID {
+			return 0, uniqueViolation("webhook_deliveries_pkey")
+		}
+	}
+	if !slices.ContainsFunc(s.webhooks, func(w database.Webhook) bool { return w.ID == arg.WebhookID }) {
+		return 0, foreignKeyViolation("fk_webhook_deliveries_webhook")
+	}
+	if arg.PostID.Valid {
+		if _, ok := s.post(arg.PostID.UUID); !ok {
+			return 0, foreignKeyViolation("fk_webhook_deliveries_post")
+		}
+	}
+
+	s.deliveries = append(s.deliveries, database.WebhookDelivery{
+		ID:            arg.ID,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		WebhookID:     arg.WebhookID,
+		PostID:        arg.PostID,
+		Payload:       arg.Payload,
+		Status:        "pending",
+		NextAttemptAt: arg.NextAttemptAt,
+	})
+	return 1, nil
+}
+
+func (s *Store) GetDueWebhookDeliveries(ctx context.Context, arg database.GetDueWebhookDeliveriesParams) ([]database.GetDueWebhookDeliveriesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetDueWebhookDeliveriesRow
+	for _, d := range s.deliveries {
+		if d.Status != "pending" || d.NextAttemptAt.After(arg.Now) {
+			continue
+		}
+		i := slices.IndexFunc(s.webhooks, func(w database.Webhook) bool { return w.ID == d.WebhookID })
+		if i < 0 {
+			continue
+		}
+		rows = append(rows, database.GetDueWebhookDeliveriesRow{
+			ID:             d.ID,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+			WebhookID:      d.WebhookID,
+			PostID:         d.PostID,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			ResponseStatus: d.ResponseStatus,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+			Url:            s.webhooks[i].Url,
+			Secret:         s.webhooks[i].Secret,
+		})
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetDueWebhookDeliveriesRow) int {
+		if c := a.NextAttemptAt.Compare(b.NextAttemptAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	if int(arg.Limit) < len(rows) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, arg database.UpdateWebhookDeliveryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deliveries {
+		d := &s.deliveries[i]
+		if d.ID == arg.ID {
+			d.UpdatedAt = arg.UpdatedAt
+			d.Status = arg.Status
+			d.Attempts = arg.Attempts
+			d.NextAttemptAt = arg.NextAttemptAt
+			d.ResponseStatus = arg.ResponseStatus
+			d.LastError = arg.LastError
+			d.DeliveredAt = arg.DeliveredAt
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetWebhookDeliveriesForUser(ctx context.Context, arg database.GetWebhookDeliveriesForUserParams) ([]database.GetWebhookDeliveriesForUserRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetWebhookDeliveriesForUserRow
+	for _, d := range s.deliveries {
+		i := slices.IndexFunc(s.webhooks, func(w database.Webhook) bool { return w.ID == d.WebhookID })
+		if i < 0 || s.webhooks[i].UserID != arg.UserID {
+			continue
+		}
+		row := database.GetWebhookDeliveriesForUserRow{
+			ID:             d.ID,
+			CreatedAt:      d.CreatedAt,
+			WebhookID:      d.WebhookID,
+			WebhookUrl:     s.webhooks[i].Url,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			ResponseStatus: d.ResponseStatus,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+		}
+		if d.PostID.Valid {
+			if p, ok := s.post(d.PostID.UUID); ok {
+				row.PostTitle.String, row.PostTitle.Valid = p.Title, true
+			}
+		}
+		rows = append(rows, row)
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetWebhookDeliveriesForUserRow) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	if int(arg.Limit) < len(rows) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
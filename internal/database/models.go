@@ -11,38 +11,127 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiKey struct {
+	ID         uuid.UUID    `json:"id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	Scope      string       `json:"scope"`
+}
+
+type DigestSubscription struct {
+	UserID     uuid.UUID    `json:"user_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Frequency  string       `json:"frequency"`
+	LastSentAt sql.NullTime `json:"last_sent_at"`
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID            uuid.UUID    `json:"id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Name          string       `json:"name"`
+	Url           string       `json:"url"`
+	UserID        uuid.UUID    `json:"user_id"`
+	LastFetchedAt sql.NullTime `json:"last_fetched_at"`
 }
 
 type FeedFollow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+}
+
+type FeedFollowTag struct {
+	FeedFollowID uuid.UUID `json:"feed_follow_id"`
+	TagID        uuid.UUID `json:"tag_id"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	FeedID      uuid.UUID      `json:"feed_id"`
+	Author      sql.NullString `json:"author"`
+}
+
+type StarredPost struct {
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	UserID      uuid.UUID      `json:"user_id"`
+	PostID      uuid.NullUUID  `json:"post_id"`
+	FeedName    string         `json:"feed_name"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	Note        string         `json:"note"`
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
 }
 
 type User struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ID           uuid.UUID      `json:"id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Name         string         `json:"name"`
+	Email        sql.NullString `json:"email"`
+	PasswordHash sql.NullString `json:"password_hash"`
+	FailedLogins int32          `json:"failed_logins"`
+	LockedUntil  sql.NullTime   `json:"locked_until"`
+}
+
+type UserPostState struct {
+	UserID    uuid.UUID    `json:"user_id"`
+	PostID    uuid.UUID    `json:"post_id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	ReadAt    sql.NullTime `json:"read_at"`
+}
+
+type Webhook struct {
+	ID        uuid.UUID      `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Url       string         `json:"url"`
+	Secret    string         `json:"secret"`
+	Format    string         `json:"format"`
+	FeedID    uuid.NullUUID  `json:"feed_id"`
+	Tag       sql.NullString `json:"tag"`
+	Keyword   sql.NullString `json:"keyword"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID      `json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	WebhookID      uuid.UUID      `json:"webhook_id"`
+	PostID         uuid.NullUUID  `json:"post_id"`
+	Payload        string         `json:"payload"`
+	Status         string         `json:"status"`
+	Attempts       int32          `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	ResponseStatus sql.NullInt32  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
 }
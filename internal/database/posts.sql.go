@@ -13,24 +13,159 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :exec
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+SELECT
+p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author,
+f.name AS feed_name,
+ups.read_at
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = $1
+AND (NOT $2::boolean OR ups.read_at IS NULL)
+AND (
+    $3::text IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = $3
+    )
+)
+AND ($4::text IS NULL OR f.url = $4)
+AND ($5::text IS NULL OR p.author ILIKE '%' || $5 || '%')
+AND ($6::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= $6)
+AND ($7::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < $7)
+AND (
+    $8::uuid IS NULL
+    OR (
+        $9::text = 'feed'
+        AND (
+            f.name > $10::text
+            OR (
+                f.name = $10::text
+                AND (COALESCE(p.published_at, p.created_at), p.id) < ($11::timestamp, $8::uuid)
+            )
+        )
+    )
+    OR (
+        $9::text = 'fetched'
+        AND (p.created_at, p.id) < ($11::timestamp, $8::uuid)
+    )
+    OR (
+        $9::text = 'published'
+        AND (COALESCE(p.published_at, p.created_at), p.id) < ($11::timestamp, $8::uuid)
+    )
+)
+ORDER BY
+    CASE WHEN $9::text = 'feed' THEN f.name END ASC,
+    CASE WHEN $9::text = 'fetched' THEN p.created_at ELSE COALESCE(p.published_at, p.created_at) END DESC,
+    p.id DESC
+LIMIT $13
+OFFSET $12
+`
+
+type BrowsePostsForUserParams struct {
+	UserID     uuid.UUID      `json:"user_id"`
+	UnreadOnly bool           `json:"unread_only"`
+	Tag        sql.NullString `json:"tag"`
+	FeedUrl    sql.NullString `json:"feed_url"`
+	Author     sql.NullString `json:"author"`
+	Since      sql.NullTime   `json:"since"`
+	Until      sql.NullTime   `json:"until"`
+	AfterID    uuid.NullUUID  `json:"after_id"`
+	Sort       string         `json:"sort"`
+	AfterFeed  sql.NullString `json:"after_feed"`
+	AfterTime  sql.NullTime   `json:"after_time"`
+	Offset     int32          `json:"offset"`
+	Limit      int32          `json:"limit"`
+}
+
+type BrowsePostsForUserRow struct {
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	FeedID      uuid.UUID      `json:"feed_id"`
+	Author      sql.NullString `json:"author"`
+	FeedName    string         `json:"feed_name"`
+	ReadAt      sql.NullTime   `json:"read_at"`
+}
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Tag,
+		arg.FeedUrl,
+		arg.Author,
+		arg.Since,
+		arg.Until,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterFeed,
+		arg.AfterTime,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.FeedName,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPost = `-- name: CreatePost :execrows
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (url) DO NOTHING
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	FeedID      uuid.UUID      `json:"feed_id"`
+	Author      sql.NullString `json:"author"`
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
-	_, err := q.db.ExecContext(ctx, createPost,
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPost,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -39,26 +174,83 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+	)
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts WHERE url = $1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, url)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+	)
+	return i, err
 }
 
 const getPostsForUSer = `-- name: GetPostsForUSer :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author
 FROM posts p
 JOIN feed_follows ff ON ff.feed_id = p.feed_id
 WHERE ff.user_id = $1
+AND (
+    $2::text IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = $2
+    )
+)
 ORDER BY p.published_at DESC
-LIMIT $2
+LIMIT $3
 `
 
 type GetPostsForUSerParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID uuid.UUID      `json:"user_id"`
+	Tag    sql.NullString `json:"tag"`
+	Limit  int32          `json:"limit"`
 }
 
 func (q *Queries) GetPostsForUSer(ctx context.Context, arg GetPostsForUSerParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUSer, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUSer, arg.UserID, arg.Tag, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +267,7 @@ func (q *Queries) GetPostsForUSer(ctx context.Context, arg GetPostsForUSerParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Author,
 		); err != nil {
 			return nil, err
 		}
@@ -88,3 +281,122 @@ func (q *Queries) GetPostsForUSer(ctx context.Context, arg GetPostsForUSerParams
 	}
 	return items, nil
 }
+
+const getPostsForUserAfter = `-- name: GetPostsForUserAfter :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = $1
+AND (p.created_at, p.id) > ($2::timestamp, $3::uuid)
+ORDER BY p.created_at, p.id
+LIMIT $4
+`
+
+type GetPostsForUserAfterParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	AfterTime time.Time `json:"after_time"`
+	AfterID   uuid.UUID `json:"after_id"`
+	Limit     int32     `json:"limit"`
+}
+
+type GetPostsForUserAfterRow struct {
+	ID          uuid.UUID      `json:"id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Title       string         `json:"title"`
+	Url         string         `json:"url"`
+	Description sql.NullString `json:"description"`
+	PublishedAt sql.NullTime   `json:"published_at"`
+	FeedID      uuid.UUID      `json:"feed_id"`
+	Author      sql.NullString `json:"author"`
+	FeedName    string         `json:"feed_name"`
+	FeedUrl     string         `json:"feed_url"`
+}
+
+func (q *Queries) GetPostsForUserAfter(ctx context.Context, arg GetPostsForUserAfterParams) ([]GetPostsForUserAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserAfter,
+		arg.UserID,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserAfterRow
+	for rows.Next() {
+		var i GetPostsForUserAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPosts = `-- name: ListPosts :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts
+ORDER BY created_at
+`
+
+func (q *Queries) ListPosts(ctx context.Context) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetPosts = `-- name: ResetPosts :exec
+DELETE FROM posts
+`
+
+func (q *Queries) ResetPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetPosts)
+	return err
+}
@@ -0,0 +1,102 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Querier interface {
+	AddFeedFollowTag(ctx context.Context, arg AddFeedFollowTagParams) error
+	BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error)
+	CountDueFeeds(ctx context.Context, before sql.NullTime) (int64, error)
+	CountStarredPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error)
+	CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error)
+	CreatePost(ctx context.Context, arg CreatePostParams) (int64, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (int64, error)
+	DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteFeed(ctx context.Context, id uuid.UUID) error
+	DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error
+	DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error)
+	DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error)
+	DeleteUnusedTags(ctx context.Context, userID uuid.UUID) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error)
+	GetAllFeeds(ctx context.Context) ([]GetAllFeedsRow, error)
+	GetDigestPosts(ctx context.Context, arg GetDigestPostsParams) ([]GetDigestPostsRow, error)
+	GetDigestSubscription(ctx context.Context, userID uuid.UUID) (DigestSubscription, error)
+	GetDigestSubscriptions(ctx context.Context) ([]GetDigestSubscriptionsRow, error)
+	GetDueWebhookDeliveries(ctx context.Context, arg GetDueWebhookDeliveriesParams) ([]GetDueWebhookDeliveriesRow, error)
+	GetFeedByURL(ctx context.Context, url string) (Feed, error)
+	GetFeedFollowForUserByURL(ctx context.Context, arg GetFeedFollowForUserByURLParams) (FeedFollow, error)
+	GetFeedFollowTagsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowTagsForUserRow, error)
+	GetFeedFollowers(ctx context.Context, feedID uuid.UUID) ([]User, error)
+	GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error)
+	GetNextFeedToFetch(ctx context.Context) (Feed, error)
+	GetPostByID(ctx context.Context, id uuid.UUID) (Post, error)
+	GetPostByURL(ctx context.Context, url string) (Post, error)
+	GetPostsForUSer(ctx context.Context, arg GetPostsForUSerParams) ([]Post, error)
+	GetPostsForUserAfter(ctx context.Context, arg GetPostsForUserAfterParams) ([]GetPostsForUserAfterRow, error)
+	GetPrunablePosts(ctx context.Context, arg GetPrunablePostsParams) ([]GetPrunablePostsRow, error)
+	GetStarredPostsForUser(ctx context.Context, arg GetStarredPostsForUserParams) ([]StarredPost, error)
+	GetUser(ctx context.Context, name string) (User, error)
+	GetUserByAPIKey(ctx context.Context, keyHash string) (GetUserByAPIKeyRow, error)
+	GetUserByEmail(ctx context.Context, email sql.NullString) (User, error)
+	// GREATEST ignores NULLs, so users without any activity fall back to
+	// updated_at.
+	GetUserStats(ctx context.Context) ([]GetUserStatsRow, error)
+	GetUsers(ctx context.Context) ([]User, error)
+	GetWebhookDeliveriesForUser(ctx context.Context, arg GetWebhookDeliveriesForUserParams) ([]GetWebhookDeliveriesForUserRow, error)
+	GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]Webhook, error)
+	GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]GetWebhooksForUserRow, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListAPIKeysForUser(ctx context.Context, userID uuid.UUID) ([]ApiKey, error)
+	ListDigestSubscriptions(ctx context.Context) ([]DigestSubscription, error)
+	ListFeedFollowTags(ctx context.Context) ([]FeedFollowTag, error)
+	ListFeedFollows(ctx context.Context) ([]FeedFollow, error)
+	ListFeeds(ctx context.Context) ([]Feed, error)
+	ListPosts(ctx context.Context) ([]Post, error)
+	ListStarredPosts(ctx context.Context) ([]StarredPost, error)
+	ListTags(ctx context.Context) ([]Tag, error)
+	ListUserPostStates(ctx context.Context) ([]UserPostState, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	MarkAllPostsRead(ctx context.Context, arg MarkAllPostsReadParams) (int64, error)
+	MarkPostRead(ctx context.Context, arg MarkPostReadParams) error
+	MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) error
+	MarkedFeedFetched(ctx context.Context, id uuid.UUID) error
+	// Counts a wrong password in a single statement so that concurrent attempts
+	// can't overwrite each other's count.
+	RecordFailedLogin(ctx context.Context, id uuid.UUID) (int32, error)
+	RemoveFeedFollowTag(ctx context.Context, arg RemoveFeedFollowTagParams) (int64, error)
+	RenameFeed(ctx context.Context, arg RenameFeedParams) (Feed, error)
+	RenameUser(ctx context.Context, arg RenameUserParams) (User, error)
+	ResetFeedFollows(ctx context.Context) error
+	ResetFeeds(ctx context.Context) error
+	ResetPosts(ctx context.Context) error
+	ResetTags(ctx context.Context) error
+	ResetUsers(ctx context.Context) error
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error)
+	SetDigestFrequency(ctx context.Context, arg SetDigestFrequencyParams) (DigestSubscription, error)
+	SetDigestSent(ctx context.Context, arg SetDigestSentParams) error
+	SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error
+	SetLoginFailures(ctx context.Context, arg SetLoginFailuresParams) error
+	SetUserEmail(ctx context.Context, arg SetUserEmailParams) error
+	SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error
+	StarPost(ctx context.Context, arg StarPostParams) (StarredPost, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	UnstarPost(ctx context.Context, arg UnstarPostParams) (int64, error)
+	UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) (Feed, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error
+}
+
+var _ Querier = (*Queries)(nil)
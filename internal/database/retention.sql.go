@@ -0,0 +1,104 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: retention.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const deletePosts = `-- name: DeletePosts :execrows
+DELETE FROM posts
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePosts, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPrunablePosts = `-- name: GetPrunablePosts :many
+SELECT
+ranked.id,
+ranked.title,
+ranked.url
+FROM (
+    SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.feed_id,
+    COALESCE(p.published_at, p.created_at) AS sort_time,
+    ROW_NUMBER() OVER (ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC) AS position
+    FROM posts p
+    WHERE p.feed_id = $1
+) ranked
+WHERE (
+    ($2::timestamp IS NOT NULL AND ranked.sort_time < $2)
+    OR ($3::bigint IS NOT NULL AND ranked.position > $3)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM starred_posts sp WHERE sp.post_id = ranked.id
+)
+AND (
+    NOT $4::boolean
+    OR NOT EXISTS (
+        SELECT 1
+        FROM feed_follows ff
+        LEFT JOIN user_post_state ups ON ups.post_id = ranked.id AND ups.user_id = ff.user_id
+        WHERE ff.feed_id = ranked.feed_id
+        AND ups.read_at IS NULL
+    )
+)
+ORDER BY ranked.position
+`
+
+type GetPrunablePostsParams struct {
+	FeedID     uuid.UUID     `json:"feed_id"`
+	OlderThan  sql.NullTime  `json:"older_than"`
+	MaxPosts   sql.NullInt64 `json:"max_posts"`
+	KeepUnread bool          `json:"keep_unread"`
+}
+
+type GetPrunablePostsRow struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Url   string    `json:"url"`
+}
+
+func (q *Queries) GetPrunablePosts(ctx context.Context, arg GetPrunablePostsParams) ([]GetPrunablePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrunablePosts,
+		arg.FeedID,
+		arg.OlderThan,
+		arg.MaxPosts,
+		arg.KeepUnread,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrunablePostsRow
+	for rows.Next() {
+		var i GetPrunablePostsRow
+		if err := rows.Scan(&i.ID, &i.Title, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
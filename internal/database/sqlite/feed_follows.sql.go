@@ -0,0 +1,220 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: feed_follows.sql
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createFeedFollow = `-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING
+id,
+created_at,
+updated_at,
+user_id,
+feed_id,
+(SELECT name FROM feeds WHERE feeds.id = feed_id) AS feed_name,
+(SELECT name FROM users WHERE users.id = user_id) AS user_name
+`
+
+type CreateFeedFollowParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+}
+
+type CreateFeedFollowRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	Name      string
+	Name_2    string
+}
+
+func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
+	row := q.db.QueryRowContext(ctx, createFeedFollow,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+	var i CreateFeedFollowRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Name,
+		&i.Name_2,
+	)
+	return i, err
+}
+
+const deleteFeedFollow = `-- name: DeleteFeedFollow :exec
+DELETE FROM feed_follows
+WHERE feed_follows.user_id = ?1
+AND feed_follows.feed_id IN (
+    SELECT feeds.id FROM feeds WHERE feeds.url = ?2
+)
+`
+
+type DeleteFeedFollowParams struct {
+	UserID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.Url)
+	return err
+}
+
+const getFeedFollowForUserByURL = `-- name: GetFeedFollowForUserByURL :one
+SELECT ff.id, ff.created_at, ff.updated_at, ff.user_id, ff.feed_id
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+WHERE ff.user_id = ?1
+AND f.url = ?2
+`
+
+type GetFeedFollowForUserByURLParams struct {
+	UserID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) GetFeedFollowForUserByURL(ctx context.Context, arg GetFeedFollowForUserByURLParams) (FeedFollow, error) {
+	row := q.db.QueryRowContext(ctx, getFeedFollowForUserByURL, arg.UserID, arg.Url)
+	var i FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+	)
+	return i, err
+}
+
+const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
+SELECT
+ff.id,
+ff.created_at,
+ff.updated_at,
+ff.user_id,
+ff.feed_id,
+f.name AS feed_name,
+f.url AS feed_url,
+u.name AS user_name,
+(
+    SELECT COUNT(*)
+    FROM posts p
+    LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+    WHERE p.feed_id = ff.feed_id
+    AND ups.read_at IS NULL
+) AS unread_count
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+INNER JOIN users u ON u.id = ff.user_id
+WHERE ff.user_id = ?1
+`
+
+type GetFeedFollowsForUserRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	FeedName    string
+	FeedUrl     string
+	UserName    string
+	UnreadCount int64
+}
+
+func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowsForUserRow
+	for rows.Next() {
+		var i GetFeedFollowsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+			&i.UnreadCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedFollows = `-- name: ListFeedFollows :many
+SELECT id, created_at, updated_at, user_id, feed_id FROM feed_follows
+ORDER BY created_at
+`
+
+func (q *Queries) ListFeedFollows(ctx context.Context) ([]FeedFollow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFollows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFollow
+	for rows.Next() {
+		var i FeedFollow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetFeedFollows = `-- name: ResetFeedFollows :exec
+DELETE FROM feed_follows
+`
+
+func (q *Queries) ResetFeedFollows(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetFeedFollows)
+	return err
+}
@@ -0,0 +1,336 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: feeds.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const countDueFeeds = `-- name: CountDueFeeds :one
+SELECT COUNT(*)
+FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at < ?1
+`
+
+func (q *Queries) CountDueFeeds(ctx context.Context, before sql.NullTime) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDueFeeds, before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createFeed = `-- name: CreateFeed :one
+
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+`
+
+type CreateFeedParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Name      string
+	Url       string
+	UserID    uuid.UUID
+}
+
+func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, createFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.UserID,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = ?1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const deletePostsForFeed = `-- name: DeletePostsForFeed :execrows
+DELETE FROM posts WHERE feed_id = ?1
+`
+
+func (q *Queries) DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getAllFeeds = `-- name: GetAllFeeds :many
+SELECT
+f.name AS feed_name,
+f.url,
+u.name AS user_name
+FROM feeds f
+JOIN users u ON f.user_id = u.id
+`
+
+type GetAllFeedsRow struct {
+	FeedName string
+	Url      string
+	UserName string
+}
+
+func (q *Queries) GetAllFeeds(ctx context.Context) ([]GetAllFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllFeedsRow
+	for rows.Next() {
+		var i GetAllFeedsRow
+		if err := rows.Scan(&i.FeedName, &i.Url, &i.UserName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedByURL = `-- name: GetFeedByURL :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = ?1
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByURL, url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+	)
+	return i, err
+}
+
+const getFeedFollowers = `-- name: GetFeedFollowers :many
+SELECT users.id, users.created_at, users.updated_at, users.name, users.email, users.password_hash, users.failed_logins, users.locked_until
+FROM feed_follows
+JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = ?1
+ORDER BY feed_follows.created_at
+`
+
+func (q *Queries) GetFeedFollowers(ctx context.Context, feedID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowers, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Email,
+			&i.PasswordHash,
+			&i.FailedLogins,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+FROM feeds
+ORDER BY last_fetched_at ASC NULLS FIRST
+LIMIT 1
+`
+
+func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+	)
+	return i, err
+}
+
+const listFeeds = `-- name: ListFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+ORDER BY name
+`
+
+func (q *Queries) ListFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markedFeedFetched = `-- name: MarkedFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+`
+
+func (q *Queries) MarkedFeedFetched(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markedFeedFetched, id)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :one
+UPDATE feeds
+SET name = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+`
+
+type RenameFeedParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, renameFeed, arg.ID, arg.Name)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+	)
+	return i, err
+}
+
+const resetFeeds = `-- name: ResetFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) ResetFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetFeeds)
+	return err
+}
+
+const setFeedOwner = `-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+`
+
+type SetFeedOwnerParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedOwner, arg.ID, arg.UserID)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = ?2,
+    last_fetched_at = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, updateFeedURL, arg.ID, arg.Url)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,137 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApiKey struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	LastUsedAt sql.NullTime
+	Scope      string
+}
+
+type DigestSubscription struct {
+	UserID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Frequency  string
+	LastSentAt sql.NullTime
+}
+
+type Feed struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	Url           string
+	UserID        uuid.UUID
+	LastFetchedAt sql.NullTime
+}
+
+type FeedFollow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+}
+
+type FeedFollowTag struct {
+	FeedFollowID uuid.UUID
+	TagID        uuid.UUID
+	CreatedAt    time.Time
+}
+
+type Post struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Author      sql.NullString
+}
+
+type StarredPost struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	PostID      uuid.NullUUID
+	FeedName    string
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	Note        string
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
+type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Email        sql.NullString
+	PasswordHash sql.NullString
+	FailedLogins int32
+	LockedUntil  sql.NullTime
+}
+
+type UserPostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	Format    string
+	FeedID    uuid.NullUUID
+	Tag       sql.NullString
+	Keyword   sql.NullString
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	WebhookID      uuid.UUID
+	PostID         uuid.NullUUID
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+}
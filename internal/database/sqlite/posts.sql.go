@@ -0,0 +1,426 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: posts.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+SELECT
+p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author,
+f.name AS feed_name,
+ups.read_at
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = ?1
+AND (CAST(?2 AS BOOLEAN) = FALSE OR ups.read_at IS NULL)
+AND (
+    CAST(?3 AS TEXT) IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = ?3
+    )
+)
+AND (CAST(?4 AS TEXT) IS NULL OR f.url = ?4)
+AND (CAST(?5 AS TEXT) IS NULL OR p.author LIKE '%' || ?5 || '%')
+AND (CAST(?6 AS TIMESTAMP) IS NULL OR COALESCE(p.published_at, p.created_at) >= ?6)
+AND (CAST(?7 AS TIMESTAMP) IS NULL OR COALESCE(p.published_at, p.created_at) < ?7)
+AND (
+    ?8 IS NULL
+    OR (
+        CAST(?9 AS TEXT) = 'feed'
+        AND (
+            f.name > CAST(?10 AS TEXT)
+            OR (
+                f.name = CAST(?10 AS TEXT)
+                AND (
+                    COALESCE(p.published_at, p.created_at) < ?11
+                    OR (
+                        COALESCE(p.published_at, p.created_at) = ?11
+                        AND p.id < ?8
+                    )
+                )
+            )
+        )
+    )
+    OR (
+        CAST(?9 AS TEXT) = 'fetched'
+        AND (
+            p.created_at < ?11
+            OR (
+                p.created_at = ?11
+                AND p.id < ?8
+            )
+        )
+    )
+    OR (
+        CAST(?9 AS TEXT) = 'published'
+        AND (
+            COALESCE(p.published_at, p.created_at) < ?11
+            OR (
+                COALESCE(p.published_at, p.created_at) = ?11
+                AND p.id < ?8
+            )
+        )
+    )
+)
+ORDER BY
+    CASE WHEN ?9 = 'feed' THEN f.name END ASC,
+    CASE WHEN ?9 = 'fetched' THEN p.created_at ELSE COALESCE(p.published_at, p.created_at) END DESC,
+    p.id DESC
+LIMIT ?13
+OFFSET ?12
+`
+
+type BrowsePostsForUserParams struct {
+	UserID     uuid.UUID
+	UnreadOnly bool
+	Tag        sql.NullString
+	FeedUrl    sql.NullString
+	Author     sql.NullString
+	Since      sql.NullTime
+	Until      sql.NullTime
+	AfterID    interface{}
+	Sort       string
+	AfterFeed  sql.NullString
+	AfterTime  sql.NullTime
+	Offset     int64
+	Limit      int64
+}
+
+type BrowsePostsForUserRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Author      sql.NullString
+	FeedName    string
+	ReadAt      sql.NullTime
+}
+
+// sqlc does not rewrite named parameters inside ORDER BY for SQLite, so the
+// sort parameter is referenced by the position sqlc assigns it above.
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.Tag,
+		arg.FeedUrl,
+		arg.Author,
+		arg.Since,
+		arg.Until,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterFeed,
+		arg.AfterTime,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.FeedName,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPost = `-- name: CreatePost :execrows
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+ON CONFLICT (url) DO NOTHING
+`
+
+type CreatePostParams struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Author      sql.NullString
+}
+
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Author,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts WHERE id = ?1
+`
+
+func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+	)
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts WHERE url = ?1
+`
+
+func (q *Queries) GetPostByURL(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, url)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+	)
+	return i, err
+}
+
+const getPostsForUSer = `-- name: GetPostsForUSer :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = ?1
+AND (
+    CAST(?2 AS TEXT) IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = ?2
+    )
+)
+ORDER BY p.published_at DESC NULLS FIRST
+LIMIT ?3
+`
+
+type GetPostsForUSerParams struct {
+	UserID uuid.UUID
+	Tag    sql.NullString
+	Limit  int64
+}
+
+// Match PostgreSQL, which puts posts without a publication date first.
+func (q *Queries) GetPostsForUSer(ctx context.Context, arg GetPostsForUSerParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUSer, arg.UserID, arg.Tag, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserAfter = `-- name: GetPostsForUserAfter :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.author, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = ?1
+AND (
+    p.created_at > ?2
+    OR (p.created_at = ?2 AND p.id > ?3)
+)
+ORDER BY p.created_at, p.id
+LIMIT ?4
+`
+
+type GetPostsForUserAfterParams struct {
+	UserID    uuid.UUID
+	AfterTime time.Time
+	AfterID   uuid.UUID
+	Limit     int64
+}
+
+type GetPostsForUserAfterRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Author      sql.NullString
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserAfter(ctx context.Context, arg GetPostsForUserAfterParams) ([]GetPostsForUserAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserAfter,
+		arg.UserID,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserAfterRow
+	for rows.Next() {
+		var i GetPostsForUserAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPosts = `-- name: ListPosts :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author FROM posts
+ORDER BY created_at
+`
+
+func (q *Queries) ListPosts(ctx context.Context) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetPosts = `-- name: ResetPosts :exec
+DELETE FROM posts
+`
+
+func (q *Queries) ResetPosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetPosts)
+	return err
+}
@@ -0,0 +1,114 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: retention.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const deletePosts = `-- name: DeletePosts :execrows
+DELETE FROM posts
+WHERE id IN (/*SLICE:ids*/?)
+`
+
+func (q *Queries) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	query := deletePosts
+	var queryParams []interface{}
+	if len(ids) > 0 {
+		for _, v := range ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	result, err := q.db.ExecContext(ctx, query, queryParams...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPrunablePosts = `-- name: GetPrunablePosts :many
+SELECT
+ranked.id,
+ranked.title,
+ranked.url
+FROM (
+    SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.feed_id,
+    COALESCE(p.published_at, p.created_at) AS sort_time,
+    ROW_NUMBER() OVER (ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC) AS position
+    FROM posts p
+    WHERE p.feed_id = ?1
+) ranked
+WHERE (
+    (CAST(?2 AS TIMESTAMP) IS NOT NULL AND ranked.sort_time < ?2)
+    OR (CAST(?3 AS BIGINT) IS NOT NULL AND ranked.position > ?3)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM starred_posts sp WHERE sp.post_id = ranked.id
+)
+AND (
+    CAST(?4 AS BOOLEAN) = FALSE
+    OR NOT EXISTS (
+        SELECT 1
+        FROM feed_follows ff
+        LEFT JOIN user_post_state ups ON ups.post_id = ranked.id AND ups.user_id = ff.user_id
+        WHERE ff.feed_id = ranked.feed_id
+        AND ups.read_at IS NULL
+    )
+)
+ORDER BY ranked.position
+`
+
+type GetPrunablePostsParams struct {
+	FeedID     uuid.UUID
+	OlderThan  sql.NullTime
+	MaxPosts   sql.NullInt64
+	KeepUnread bool
+}
+
+type GetPrunablePostsRow struct {
+	ID    uuid.UUID
+	Title string
+	Url   string
+}
+
+func (q *Queries) GetPrunablePosts(ctx context.Context, arg GetPrunablePostsParams) ([]GetPrunablePostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrunablePosts,
+		arg.FeedID,
+		arg.OlderThan,
+		arg.MaxPosts,
+		arg.KeepUnread,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrunablePostsRow
+	for rows.Next() {
+		var i GetPrunablePostsRow
+		if err := rows.Scan(&i.ID, &i.Title, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,181 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: starred_posts.sql
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const countStarredPostsForUser = `-- name: CountStarredPostsForUser :one
+SELECT COUNT(*)
+FROM starred_posts
+WHERE user_id = ?1
+`
+
+func (q *Queries) CountStarredPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStarredPostsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getStarredPostsForUser = `-- name: GetStarredPostsForUser :many
+SELECT id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note
+FROM starred_posts
+WHERE user_id = ?1
+ORDER BY created_at DESC, id DESC
+LIMIT ?2
+OFFSET ?3
+`
+
+type GetStarredPostsForUserParams struct {
+	UserID uuid.UUID
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) GetStarredPostsForUser(ctx context.Context, arg GetStarredPostsForUserParams) ([]StarredPost, error) {
+	rows, err := q.db.QueryContext(ctx, getStarredPostsForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StarredPost
+	for rows.Next() {
+		var i StarredPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.PostID,
+			&i.FeedName,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStarredPosts = `-- name: ListStarredPosts :many
+SELECT id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note FROM starred_posts
+ORDER BY created_at
+`
+
+func (q *Queries) ListStarredPosts(ctx context.Context) ([]StarredPost, error) {
+	rows, err := q.db.QueryContext(ctx, listStarredPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StarredPost
+	for rows.Next() {
+		var i StarredPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.PostID,
+			&i.FeedName,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const starPost = `-- name: StarPost :one
+INSERT INTO starred_posts (id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note)
+SELECT ?1, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), ?2, p.id, f.name, p.title, p.url, p.description, p.published_at, ?3
+FROM posts p
+JOIN feeds f ON f.id = p.feed_id
+WHERE p.id = ?4
+ON CONFLICT (user_id, url) DO UPDATE
+SET note = excluded.note,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+RETURNING id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note
+`
+
+type StarPostParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	Note   string
+	PostID uuid.UUID
+}
+
+func (q *Queries) StarPost(ctx context.Context, arg StarPostParams) (StarredPost, error) {
+	row := q.db.QueryRowContext(ctx, starPost,
+		arg.ID,
+		arg.UserID,
+		arg.Note,
+		arg.PostID,
+	)
+	var i StarredPost
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.PostID,
+		&i.FeedName,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.Note,
+	)
+	return i, err
+}
+
+const unstarPost = `-- name: UnstarPost :execrows
+DELETE FROM starred_posts
+WHERE user_id = ?1
+AND (
+    id = CAST(?2 AS TEXT)
+    OR post_id = CAST(?2 AS TEXT)
+    OR url = CAST(?2 AS TEXT)
+)
+`
+
+type UnstarPostParams struct {
+	UserID uuid.UUID
+	Ref    string
+}
+
+func (q *Queries) UnstarPost(ctx context.Context, arg UnstarPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unstarPost, arg.UserID, arg.Ref)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
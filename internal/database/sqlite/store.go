@@ -0,0 +1,713 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Store runs the SQLite queries behind the same database.Querier interface
+// as the PostgreSQL queries, converting between the two sets of generated
+// types.
+type Store struct {
+	q  *Queries
+	db *sql.DB
+}
+
+var _ database.Store = (*Store)(nil)
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{
+		q:  New(utcDB{db}),
+		db: db,
+	}
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(database.Querier) error) error {
+	return database.RunInTx(ctx, s.db, func(tx *sql.Tx) error {
+		// Queries.WithTx would drop the utcDB wrapper, so wrap tx directly.
+		return fn(&Store{q: New(utcDB{tx})})
+	})
+}
+
+// utcDB stores every timestamp in UTC so that SQLite, which compares
+// timestamps as text, orders them consistently with the ones it generates
+// itself.
+type utcDB struct {
+	db DBTX
+}
+
+func (u utcDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return u.db.ExecContext(ctx, query, toUTC(args)...)
+}
+
+func (u utcDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return u.db.PrepareContext(ctx, query)
+}
+
+func (u utcDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return u.db.QueryContext(ctx, query, toUTC(args)...)
+}
+
+func (u utcDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return u.db.QueryRowContext(ctx, query, toUTC(args)...)
+}
+
+func toUTC(args []interface{}) []interface{} {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			args[i] = v.UTC()
+		case sql.NullTime:
+			if v.Valid {
+				args[i] = sql.NullTime{Time: v.Time.UTC(), Valid: true}
+			}
+		}
+	}
+	return args
+}
+
+func (s *Store) BrowsePostsForUser(ctx context.Context, arg database.BrowsePostsForUserParams) ([]database.BrowsePostsForUserRow, error) {
+	rows, err := s.q.BrowsePostsForUser(ctx, BrowsePostsForUserParams{
+		UserID:     arg.UserID,
+		UnreadOnly: arg.UnreadOnly,
+		Tag:        arg.Tag,
+		FeedUrl:    arg.FeedUrl,
+		Author:     arg.Author,
+		Since:      arg.Since,
+		Until:      arg.Until,
+		AfterID:    arg.AfterID,
+		Sort:       arg.Sort,
+		AfterFeed:  arg.AfterFeed,
+		AfterTime:  arg.AfterTime,
+		Offset:     int64(arg.Offset),
+		Limit:      int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.BrowsePostsForUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.BrowsePostsForUserRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	row, err := s.q.CreateFeedFollow(ctx, CreateFeedFollowParams(arg))
+	return database.CreateFeedFollowRow{
+		ID:        row.ID,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		UserID:    row.UserID,
+		FeedID:    row.FeedID,
+		FeedName:  row.Name,
+		UserName:  row.Name_2,
+	}, err
+}
+
+func (s *Store) GetPostsForUSer(ctx context.Context, arg database.GetPostsForUSerParams) ([]database.Post, error) {
+	rows, err := s.q.GetPostsForUSer(ctx, GetPostsForUSerParams{
+		UserID: arg.UserID,
+		Tag:    arg.Tag,
+		Limit:  int64(arg.Limit),
+	})
+	return convertPosts(rows, err)
+}
+
+func (s *Store) GetPostsForUserAfter(ctx context.Context, arg database.GetPostsForUserAfterParams) ([]database.GetPostsForUserAfterRow, error) {
+	rows, err := s.q.GetPostsForUserAfter(ctx, GetPostsForUserAfterParams{
+		UserID:    arg.UserID,
+		AfterTime: arg.AfterTime,
+		AfterID:   arg.AfterID,
+		Limit:     int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPostsForUserAfterRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetPostsForUserAfterRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetStarredPostsForUser(ctx context.Context, arg database.GetStarredPostsForUserParams) ([]database.StarredPost, error) {
+	rows, err := s.q.GetStarredPostsForUser(ctx, GetStarredPostsForUserParams{
+		UserID: arg.UserID,
+		Limit:  int64(arg.Limit),
+		Offset: int64(arg.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.StarredPost, len(rows))
+	for i, row := range rows {
+		items[i] = database.StarredPost(row)
+	}
+	return items, nil
+}
+
+func convertPosts(rows []Post, err error) ([]database.Post, error) {
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Post, len(rows))
+	for i, row := range rows {
+		items[i] = database.Post(row)
+	}
+	return items, nil
+}
+
+func convertWebhooks(rows []Webhook, err error) ([]database.Webhook, error) {
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = database.Webhook(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetUserStats(ctx context.Context) ([]database.GetUserStatsRow, error) {
+	rows, err := s.q.GetUserStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetUserStatsRow, len(rows))
+	for i, row := range rows {
+		lastActive, err := parseTime(row.LastActiveAt)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = database.GetUserStatsRow{
+			ID:           row.ID,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+			Name:         row.Name,
+			Email:        row.Email,
+			PasswordHash: row.PasswordHash,
+			FailedLogins: row.FailedLogins,
+			LockedUntil:  row.LockedUntil,
+			FollowCount:  row.FollowCount,
+			FeedCount:    row.FeedCount,
+			LastActiveAt: lastActive,
+		}
+	}
+	return items, nil
+}
+
+// parseTime converts a timestamp computed by an expression. The driver only
+// parses columns declared as TIMESTAMP, so expressions come back as text.
+func parseTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		t, err := time.Parse("2006-01-02 15:04:05.999999999-07:00", v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse timestamp %q: %w", v, err)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected timestamp type %T", v)
+	}
+}
+
+func (s *Store) AddFeedFollowTag(ctx context.Context, arg database.AddFeedFollowTagParams) error {
+	return s.q.AddFeedFollowTag(ctx, AddFeedFollowTagParams(arg))
+}
+
+func (s *Store) CountDueFeeds(ctx context.Context, before sql.NullTime) (int64, error) {
+	return s.q.CountDueFeeds(ctx, before)
+}
+
+func (s *Store) CountStarredPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.q.CountStarredPostsForUser(ctx, userID)
+}
+
+func (s *Store) CreateAPIKey(ctx context.Context, arg database.CreateAPIKeyParams) (database.ApiKey, error) {
+	row, err := s.q.CreateAPIKey(ctx, CreateAPIKeyParams(arg))
+	return database.ApiKey(row), err
+}
+
+func (s *Store) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	row, err := s.q.CreateFeed(ctx, CreateFeedParams(arg))
+	return database.Feed(row), err
+}
+
+func (s *Store) CreatePost(ctx context.Context, arg database.CreatePostParams) (int64, error) {
+	return s.q.CreatePost(ctx, CreatePostParams(arg))
+}
+
+func (s *Store) CreateTag(ctx context.Context, arg database.CreateTagParams) (database.Tag, error) {
+	row, err := s.q.CreateTag(ctx, CreateTagParams(arg))
+	return database.Tag(row), err
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row, err := s.q.CreateUser(ctx, CreateUserParams(arg))
+	return database.User(row), err
+}
+
+func (s *Store) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	row, err := s.q.CreateWebhook(ctx, CreateWebhookParams(arg))
+	return database.Webhook(row), err
+}
+
+func (s *Store) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) (int64, error) {
+	return s.q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams(arg))
+}
+
+func (s *Store) DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.q.DeleteDigestSubscription(ctx, userID)
+}
+
+func (s *Store) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteFeed(ctx, id)
+}
+
+func (s *Store) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	return s.q.DeleteFeedFollow(ctx, DeleteFeedFollowParams(arg))
+}
+
+func (s *Store) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return s.q.DeletePosts(ctx, ids)
+}
+
+func (s *Store) DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	return s.q.DeletePostsForFeed(ctx, feedID)
+}
+
+func (s *Store) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteUser(ctx, id)
+}
+
+func (s *Store) DeleteUnusedTags(ctx context.Context, userID uuid.UUID) error {
+	return s.q.DeleteUnusedTags(ctx, userID)
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) (int64, error) {
+	return s.q.DeleteWebhook(ctx, DeleteWebhookParams(arg))
+}
+
+func (s *Store) GetAllFeeds(ctx context.Context) ([]database.GetAllFeedsRow, error) {
+	rows, err := s.q.GetAllFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetAllFeedsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetAllFeedsRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetDigestPosts(ctx context.Context, arg database.GetDigestPostsParams) ([]database.GetDigestPostsRow, error) {
+	rows, err := s.q.GetDigestPosts(ctx, GetDigestPostsParams{
+		UserID: arg.UserID,
+		Since:  arg.Since,
+		Until:  arg.Until,
+		Limit:  int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetDigestPostsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetDigestPostsRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetDigestSubscription(ctx context.Context, userID uuid.UUID) (database.DigestSubscription, error) {
+	row, err := s.q.GetDigestSubscription(ctx, userID)
+	return database.DigestSubscription(row), err
+}
+
+func (s *Store) GetDigestSubscriptions(ctx context.Context) ([]database.GetDigestSubscriptionsRow, error) {
+	rows, err := s.q.GetDigestSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetDigestSubscriptionsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetDigestSubscriptionsRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetDueWebhookDeliveries(ctx context.Context, arg database.GetDueWebhookDeliveriesParams) ([]database.GetDueWebhookDeliveriesRow, error) {
+	rows, err := s.q.GetDueWebhookDeliveries(ctx, GetDueWebhookDeliveriesParams{
+		Now:   arg.Now,
+		Limit: int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetDueWebhookDeliveriesRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetDueWebhookDeliveriesRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	row, err := s.q.GetFeedByURL(ctx, url)
+	return database.Feed(row), err
+}
+
+func (s *Store) GetFeedFollowForUserByURL(ctx context.Context, arg database.GetFeedFollowForUserByURLParams) (database.FeedFollow, error) {
+	row, err := s.q.GetFeedFollowForUserByURL(ctx, GetFeedFollowForUserByURLParams(arg))
+	return database.FeedFollow(row), err
+}
+
+func (s *Store) GetFeedFollowers(ctx context.Context, feedID uuid.UUID) ([]database.User, error) {
+	rows, err := s.q.GetFeedFollowers(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.User, len(rows))
+	for i, row := range rows {
+		items[i] = database.User(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetFeedFollowTagsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowTagsForUserRow, error) {
+	rows, err := s.q.GetFeedFollowTagsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedFollowTagsForUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetFeedFollowTagsForUserRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	rows, err := s.q.GetFeedFollowsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedFollowsForUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetFeedFollowsForUserRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	row, err := s.q.GetNextFeedToFetch(ctx)
+	return database.Feed(row), err
+}
+
+func (s *Store) GetPostByID(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	row, err := s.q.GetPostByID(ctx, id)
+	return database.Post(row), err
+}
+
+func (s *Store) GetPostByURL(ctx context.Context, url string) (database.Post, error) {
+	row, err := s.q.GetPostByURL(ctx, url)
+	return database.Post(row), err
+}
+
+func (s *Store) GetPrunablePosts(ctx context.Context, arg database.GetPrunablePostsParams) ([]database.GetPrunablePostsRow, error) {
+	rows, err := s.q.GetPrunablePosts(ctx, GetPrunablePostsParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPrunablePostsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetPrunablePostsRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetUser(ctx context.Context, name string) (database.User, error) {
+	row, err := s.q.GetUser(ctx, name)
+	return database.User(row), err
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email sql.NullString) (database.User, error) {
+	row, err := s.q.GetUserByEmail(ctx, email)
+	return database.User(row), err
+}
+
+func (s *Store) GetUserByAPIKey(ctx context.Context, keyHash string) (database.GetUserByAPIKeyRow, error) {
+	row, err := s.q.GetUserByAPIKey(ctx, keyHash)
+	return database.GetUserByAPIKeyRow{User: database.User(row.User), Scope: row.Scope}, err
+}
+
+func (s *Store) GetUsers(ctx context.Context) ([]database.User, error) {
+	rows, err := s.q.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.User, len(rows))
+	for i, row := range rows {
+		items[i] = database.User(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetWebhookDeliveriesForUser(ctx context.Context, arg database.GetWebhookDeliveriesForUserParams) ([]database.GetWebhookDeliveriesForUserRow, error) {
+	rows, err := s.q.GetWebhookDeliveriesForUser(ctx, GetWebhookDeliveriesForUserParams{
+		UserID: arg.UserID,
+		Limit:  int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetWebhookDeliveriesForUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetWebhookDeliveriesForUserRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Webhook, error) {
+	rows, err := s.q.GetWebhooksForFeed(ctx, feedID)
+	return convertWebhooks(rows, err)
+}
+
+func (s *Store) GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]database.GetWebhooksForUserRow, error) {
+	rows, err := s.q.GetWebhooksForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetWebhooksForUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetWebhooksForUserRow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListAPIKeys(ctx context.Context) ([]database.ApiKey, error) {
+	rows, err := s.q.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.ApiKey, len(rows))
+	for i, row := range rows {
+		items[i] = database.ApiKey(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListAPIKeysForUser(ctx context.Context, userID uuid.UUID) ([]database.ApiKey, error) {
+	rows, err := s.q.ListAPIKeysForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.ApiKey, len(rows))
+	for i, row := range rows {
+		items[i] = database.ApiKey(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListDigestSubscriptions(ctx context.Context) ([]database.DigestSubscription, error) {
+	rows, err := s.q.ListDigestSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.DigestSubscription, len(rows))
+	for i, row := range rows {
+		items[i] = database.DigestSubscription(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListFeeds(ctx context.Context) ([]database.Feed, error) {
+	rows, err := s.q.ListFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Feed, len(rows))
+	for i, row := range rows {
+		items[i] = database.Feed(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListFeedFollowTags(ctx context.Context) ([]database.FeedFollowTag, error) {
+	rows, err := s.q.ListFeedFollowTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.FeedFollowTag, len(rows))
+	for i, row := range rows {
+		items[i] = database.FeedFollowTag(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	rows, err := s.q.ListFeedFollows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.FeedFollow, len(rows))
+	for i, row := range rows {
+		items[i] = database.FeedFollow(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListPosts(ctx context.Context) ([]database.Post, error) {
+	return convertPosts(s.q.ListPosts(ctx))
+}
+
+func (s *Store) ListStarredPosts(ctx context.Context) ([]database.StarredPost, error) {
+	rows, err := s.q.ListStarredPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.StarredPost, len(rows))
+	for i, row := range rows {
+		items[i] = database.StarredPost(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListTags(ctx context.Context) ([]database.Tag, error) {
+	rows, err := s.q.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Tag, len(rows))
+	for i, row := range rows {
+		items[i] = database.Tag(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListUserPostStates(ctx context.Context) ([]database.UserPostState, error) {
+	rows, err := s.q.ListUserPostStates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.UserPostState, len(rows))
+	for i, row := range rows {
+		items[i] = database.UserPostState(row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]database.Webhook, error) {
+	rows, err := s.q.ListWebhooks(ctx)
+	return convertWebhooks(rows, err)
+}
+
+func (s *Store) MarkAllPostsRead(ctx context.Context, arg database.MarkAllPostsReadParams) (int64, error) {
+	return s.q.MarkAllPostsRead(ctx, MarkAllPostsReadParams(arg))
+}
+
+func (s *Store) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	return s.q.MarkPostRead(ctx, MarkPostReadParams(arg))
+}
+
+func (s *Store) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	return s.q.MarkPostUnread(ctx, MarkPostUnreadParams(arg))
+}
+
+func (s *Store) MarkedFeedFetched(ctx context.Context, id uuid.UUID) error {
+	return s.q.MarkedFeedFetched(ctx, id)
+}
+
+func (s *Store) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int32, error) {
+	return s.q.RecordFailedLogin(ctx, id)
+}
+
+func (s *Store) RemoveFeedFollowTag(ctx context.Context, arg database.RemoveFeedFollowTagParams) (int64, error) {
+	return s.q.RemoveFeedFollowTag(ctx, RemoveFeedFollowTagParams(arg))
+}
+
+func (s *Store) RenameFeed(ctx context.Context, arg database.RenameFeedParams) (database.Feed, error) {
+	row, err := s.q.RenameFeed(ctx, RenameFeedParams(arg))
+	return database.Feed(row), err
+}
+
+func (s *Store) RenameUser(ctx context.Context, arg database.RenameUserParams) (database.User, error) {
+	row, err := s.q.RenameUser(ctx, RenameUserParams(arg))
+	return database.User(row), err
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, arg database.RevokeAPIKeyParams) (int64, error) {
+	return s.q.RevokeAPIKey(ctx, RevokeAPIKeyParams(arg))
+}
+
+func (s *Store) ResetFeedFollows(ctx context.Context) error {
+	return s.q.ResetFeedFollows(ctx)
+}
+
+func (s *Store) ResetFeeds(ctx context.Context) error {
+	return s.q.ResetFeeds(ctx)
+}
+
+func (s *Store) ResetPosts(ctx context.Context) error {
+	return s.q.ResetPosts(ctx)
+}
+
+func (s *Store) ResetTags(ctx context.Context) error {
+	return s.q.ResetTags(ctx)
+}
+
+func (s *Store) ResetUsers(ctx context.Context) error {
+	return s.q.ResetUsers(ctx)
+}
+
+func (s *Store) SetDigestFrequency(ctx context.Context, arg database.SetDigestFrequencyParams) (database.DigestSubscription, error) {
+	row, err := s.q.SetDigestFrequency(ctx, SetDigestFrequencyParams(arg))
+	return database.DigestSubscription(row), err
+}
+
+func (s *Store) SetDigestSent(ctx context.Context, arg database.SetDigestSentParams) error {
+	return s.q.SetDigestSent(ctx, SetDigestSentParams(arg))
+}
+
+func (s *Store) SetFeedOwner(ctx context.Context, arg database.SetFeedOwnerParams) error {
+	return s.q.SetFeedOwner(ctx, SetFeedOwnerParams(arg))
+}
+
+func (s *Store) SetLoginFailures(ctx context.Context, arg database.SetLoginFailuresParams) error {
+	return s.q.SetLoginFailures(ctx, SetLoginFailuresParams(arg))
+}
+
+func (s *Store) SetUserEmail(ctx context.Context, arg database.SetUserEmailParams) error {
+	return s.q.SetUserEmail(ctx, SetUserEmailParams(arg))
+}
+
+func (s *Store) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	return s.q.SetUserPassword(ctx, SetUserPasswordParams(arg))
+}
+
+func (s *Store) StarPost(ctx context.Context, arg database.StarPostParams) (database.StarredPost, error) {
+	row, err := s.q.StarPost(ctx, StarPostParams(arg))
+	return database.StarredPost(row), err
+}
+
+func (s *Store) TouchAPIKey(ctx context.Context, arg database.TouchAPIKeyParams) error {
+	return s.q.TouchAPIKey(ctx, TouchAPIKeyParams(arg))
+}
+
+func (s *Store) UnstarPost(ctx context.Context, arg database.UnstarPostParams) (int64, error) {
+	return s.q.UnstarPost(ctx, UnstarPostParams(arg))
+}
+
+func (s *Store) UpdateFeedURL(ctx context.Context, arg database.UpdateFeedURLParams) (database.Feed, error) {
+	row, err := s.q.UpdateFeedURL(ctx, UpdateFeedURLParams(arg))
+	return database.Feed(row), err
+}
+
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, arg database.UpdateWebhookDeliveryParams) error {
+	return s.q.UpdateWebhookDelivery(ctx, UpdateWebhookDeliveryParams(arg))
+}
@@ -0,0 +1,320 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: users.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, name, email, password_hash)
+VALUES (
+    ?1,
+    ?2,
+    ?3,
+    ?4,
+    ?5,
+    ?6
+)
+RETURNING id, created_at, updated_at, name, email, password_hash, failed_logins, locked_until
+`
+
+type CreateUserParams struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Email        sql.NullString
+	PasswordHash sql.NullString
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Email,
+		arg.PasswordHash,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FailedLogins,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users WHERE id = ?1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, name, email, password_hash, failed_logins, locked_until FROM users WHERE name = ?1
+`
+
+func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, name)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FailedLogins,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, created_at, updated_at, name, email, password_hash, failed_logins, locked_until FROM users WHERE email = ?1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FailedLogins,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const getUserStats = `-- name: GetUserStats :many
+SELECT
+    users.id, users.created_at, users.updated_at, users.name, users.email, users.password_hash, users.failed_logins, users.locked_until,
+    (SELECT count(*) FROM feed_follows WHERE feed_follows.user_id = users.id) AS follow_count,
+    (SELECT count(*) FROM feeds WHERE feeds.user_id = users.id) AS feed_count,
+    max(
+        users.updated_at,
+        COALESCE((SELECT max(created_at) FROM feeds WHERE feeds.user_id = users.id), users.updated_at),
+        COALESCE((SELECT max(created_at) FROM feed_follows WHERE feed_follows.user_id = users.id), users.updated_at),
+        COALESCE((SELECT max(read_at) FROM user_post_state WHERE user_post_state.user_id = users.id), users.updated_at),
+        COALESCE((SELECT max(created_at) FROM starred_posts WHERE starred_posts.user_id = users.id), users.updated_at)
+    ) AS last_active_at
+FROM users
+ORDER BY users.name
+`
+
+type GetUserStatsRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	Email        sql.NullString
+	PasswordHash sql.NullString
+	FailedLogins int32
+	LockedUntil  sql.NullTime
+	FollowCount  int64
+	FeedCount    int64
+	LastActiveAt interface{}
+}
+
+// Multi-argument max() returns NULL if any argument is NULL, so each
+// subquery falls back to updated_at. The timestamps share one text format
+// and compare correctly as strings.
+func (q *Queries) GetUserStats(ctx context.Context) ([]GetUserStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserStats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserStatsRow
+	for rows.Next() {
+		var i GetUserStatsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Email,
+			&i.PasswordHash,
+			&i.FailedLogins,
+			&i.LockedUntil,
+			&i.FollowCount,
+			&i.FeedCount,
+			&i.LastActiveAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsers = `-- name: GetUsers :many
+SELECT id, created_at, updated_at, name, email, password_hash, failed_logins, locked_until FROM users
+`
+
+func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Email,
+			&i.PasswordHash,
+			&i.FailedLogins,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_logins = failed_logins + 1
+WHERE id = ?1
+RETURNING failed_logins
+`
+
+// Counts a wrong password in a single statement so that concurrent attempts
+// can't overwrite each other's count.
+func (q *Queries) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, recordFailedLogin, id)
+	var failed_logins int32
+	err := row.Scan(&failed_logins)
+	return failed_logins, err
+}
+
+const renameUser = `-- name: RenameUser :one
+UPDATE users
+SET name = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+RETURNING id, created_at, updated_at, name, email, password_hash, failed_logins, locked_until
+`
+
+type RenameUserParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, renameUser, arg.ID, arg.Name)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Email,
+		&i.PasswordHash,
+		&i.FailedLogins,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const resetUsers = `-- name: ResetUsers :exec
+DELETE FROM users
+`
+
+func (q *Queries) ResetUsers(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetUsers)
+	return err
+}
+
+const setLoginFailures = `-- name: SetLoginFailures :exec
+UPDATE users
+SET failed_logins = ?2,
+    locked_until = ?3
+WHERE id = ?1
+`
+
+type SetLoginFailuresParams struct {
+	ID           uuid.UUID
+	FailedLogins int32
+	LockedUntil  sql.NullTime
+}
+
+func (q *Queries) SetLoginFailures(ctx context.Context, arg SetLoginFailuresParams) error {
+	_, err := q.db.ExecContext(ctx, setLoginFailures, arg.ID, arg.FailedLogins, arg.LockedUntil)
+	return err
+}
+
+const setUserEmail = `-- name: SetUserEmail :exec
+UPDATE users
+SET email = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+`
+
+type SetUserEmailParams struct {
+	ID    uuid.UUID
+	Email sql.NullString
+}
+
+func (q *Queries) SetUserEmail(ctx context.Context, arg SetUserEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setUserEmail, arg.ID, arg.Email)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = ?2,
+    failed_logins = 0,
+    locked_until = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+`
+
+type SetUserPasswordParams struct {
+	ID           uuid.UUID
+	PasswordHash sql.NullString
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
@@ -0,0 +1,443 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: webhooks.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, secret, format, feed_id, tag, keyword)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING id, created_at, updated_at, user_id, url, secret, format, feed_id, tag, keyword
+`
+
+type CreateWebhookParams struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	Format    string
+	FeedID    uuid.NullUUID
+	Tag       sql.NullString
+	Keyword   sql.NullString
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Url,
+		arg.Secret,
+		arg.Format,
+		arg.FeedID,
+		arg.Tag,
+		arg.Keyword,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Url,
+		&i.Secret,
+		&i.Format,
+		&i.FeedID,
+		&i.Tag,
+		&i.Keyword,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :execrows
+INSERT INTO webhook_deliveries (id, created_at, updated_at, webhook_id, post_id, payload, status, next_attempt_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, 'pending', ?7)
+ON CONFLICT (webhook_id, post_id) DO NOTHING
+`
+
+type CreateWebhookDeliveryParams struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WebhookID     uuid.UUID
+	PostID        uuid.NullUUID
+	Payload       string
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createWebhookDelivery,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.WebhookID,
+		arg.PostID,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE user_id = ?1
+AND id = CAST(?2 AS TEXT)
+`
+
+type DeleteWebhookParams struct {
+	UserID uuid.UUID
+	Ref    string
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhook, arg.UserID, arg.Ref)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDueWebhookDeliveries = `-- name: GetDueWebhookDeliveries :many
+SELECT d.id, d.created_at, d.updated_at, d.webhook_id, d.post_id, d.payload, d.status, d.attempts, d.next_attempt_at, d.response_status, d.last_error, d.delivered_at, w.url, w.secret
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.status = 'pending'
+AND d.next_attempt_at <= ?1
+ORDER BY d.next_attempt_at, d.id
+LIMIT ?2
+`
+
+type GetDueWebhookDeliveriesParams struct {
+	Now   time.Time
+	Limit int64
+}
+
+type GetDueWebhookDeliveriesRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	WebhookID      uuid.UUID
+	PostID         uuid.NullUUID
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+	Url            string
+	Secret         string
+}
+
+func (q *Queries) GetDueWebhookDeliveries(ctx context.Context, arg GetDueWebhookDeliveriesParams) ([]GetDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDueWebhookDeliveries, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i GetDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.WebhookID,
+			&i.PostID,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhookDeliveriesForUser = `-- name: GetWebhookDeliveriesForUser :many
+SELECT
+d.id,
+d.created_at,
+d.webhook_id,
+w.url AS webhook_url,
+p.title AS post_title,
+d.status,
+d.attempts,
+d.next_attempt_at,
+d.response_status,
+d.last_error,
+d.delivered_at
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+LEFT JOIN posts p ON p.id = d.post_id
+WHERE w.user_id = ?1
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT ?2
+`
+
+type GetWebhookDeliveriesForUserParams struct {
+	UserID uuid.UUID
+	Limit  int64
+}
+
+type GetWebhookDeliveriesForUserRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	WebhookID      uuid.UUID
+	WebhookUrl     string
+	PostTitle      sql.NullString
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+}
+
+func (q *Queries) GetWebhookDeliveriesForUser(ctx context.Context, arg GetWebhookDeliveriesForUserParams) ([]GetWebhookDeliveriesForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveriesForUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWebhookDeliveriesForUserRow
+	for rows.Next() {
+		var i GetWebhookDeliveriesForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.WebhookID,
+			&i.WebhookUrl,
+			&i.PostTitle,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhooksForFeed = `-- name: GetWebhooksForFeed :many
+SELECT w.id, w.created_at, w.updated_at, w.user_id, w.url, w.secret, w.format, w.feed_id, w.tag, w.keyword
+FROM webhooks w
+JOIN feed_follows ff ON ff.user_id = w.user_id AND ff.feed_id = ?1
+WHERE (w.feed_id IS NULL OR w.feed_id = ?1)
+AND (
+    w.tag IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = w.tag
+    )
+)
+ORDER BY w.created_at
+`
+
+func (q *Queries) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Url,
+			&i.Secret,
+			&i.Format,
+			&i.FeedID,
+			&i.Tag,
+			&i.Keyword,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWebhooksForUser = `-- name: GetWebhooksForUser :many
+SELECT w.id, w.created_at, w.updated_at, w.user_id, w.url, w.secret, w.format, w.feed_id, w.tag, w.keyword, f.url AS feed_url
+FROM webhooks w
+LEFT JOIN feeds f ON f.id = w.feed_id
+WHERE w.user_id = ?1
+ORDER BY w.created_at
+`
+
+type GetWebhooksForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Url       string
+	Secret    string
+	Format    string
+	FeedID    uuid.NullUUID
+	Tag       sql.NullString
+	Keyword   sql.NullString
+	FeedUrl   sql.NullString
+}
+
+func (q *Queries) GetWebhooksForUser(ctx context.Context, userID uuid.UUID) ([]GetWebhooksForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWebhooksForUserRow
+	for rows.Next() {
+		var i GetWebhooksForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Url,
+			&i.Secret,
+			&i.Format,
+			&i.FeedID,
+			&i.Tag,
+			&i.Keyword,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooks = `-- name: ListWebhooks :many
+SELECT id, created_at, updated_at, user_id, url, secret, format, feed_id, tag, keyword FROM webhooks
+ORDER BY created_at
+`
+
+func (q *Queries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Url,
+			&i.Secret,
+			&i.Format,
+			&i.FeedID,
+			&i.Tag,
+			&i.Keyword,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET updated_at = ?2,
+    status = ?3,
+    attempts = ?4,
+    next_attempt_at = ?5,
+    response_status = ?6,
+    last_error = ?7,
+    delivered_at = ?8
+WHERE id = ?1
+`
+
+type UpdateWebhookDeliveryParams struct {
+	ID             uuid.UUID
+	UpdatedAt      time.Time
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus sql.NullInt32
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDelivery,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Status,
+		arg.Attempts,
+		arg.NextAttemptAt,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.DeliveredAt,
+	)
+	return err
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Store is a Querier that can also run a group of queries atomically.
+type Store interface {
+	Querier
+	// ExecTx runs fn inside a transaction, committing if it returns nil
+	// and rolling back otherwise.
+	ExecTx(ctx context.Context, fn func(Querier) error) error
+}
+
+// PostgresStore is the Store backed by PostgreSQL.
+type PostgresStore struct {
+	*Queries
+	db *sql.DB
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+func NewStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{
+		Queries: New(db),
+		db:      db,
+	}
+}
+
+func (s *PostgresStore) ExecTx(ctx context.Context, fn func(Querier) error) error {
+	return RunInTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(s.WithTx(tx))
+	})
+}
+
+// RunInTx begins a transaction on db, runs fn and commits, rolling back if
+// fn returns an error.
+func RunInTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: tags.sql
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const addFeedFollowTag = `-- name: AddFeedFollowTag :exec
+INSERT INTO feed_follow_tags (feed_follow_id, tag_id, created_at)
+VALUES ($1, $2, now())
+ON CONFLICT (feed_follow_id, tag_id) DO NOTHING
+`
+
+type AddFeedFollowTagParams struct {
+	FeedFollowID uuid.UUID `json:"feed_follow_id"`
+	TagID        uuid.UUID `json:"tag_id"`
+}
+
+func (q *Queries) AddFeedFollowTag(ctx context.Context, arg AddFeedFollowTagParams) error {
+	_, err := q.db.ExecContext(ctx, addFeedFollowTag, arg.FeedFollowID, arg.TagID)
+	return err
+}
+
+const createTag = `-- name: CreateTag :one
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, name) DO UPDATE
+SET updated_at = tags.updated_at
+RETURNING id, created_at, updated_at, user_id, name
+`
+
+type CreateTagParams struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
+func (q *Queries) CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, createTag,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+	)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const deleteUnusedTags = `-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE user_id = $1
+AND NOT EXISTS (
+    SELECT 1 FROM feed_follow_tags fft WHERE fft.tag_id = tags.id
+)
+`
+
+func (q *Queries) DeleteUnusedTags(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUnusedTags, userID)
+	return err
+}
+
+const getFeedFollowTagsForUser = `-- name: GetFeedFollowTagsForUser :many
+SELECT
+fft.feed_follow_id,
+t.name AS tag_name
+FROM feed_follow_tags fft
+INNER JOIN tags t ON t.id = fft.tag_id
+WHERE t.user_id = $1
+ORDER BY t.name
+`
+
+type GetFeedFollowTagsForUserRow struct {
+	FeedFollowID uuid.UUID `json:"feed_follow_id"`
+	TagName      string    `json:"tag_name"`
+}
+
+func (q *Queries) GetFeedFollowTagsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowTagsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowTagsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowTagsForUserRow
+	for rows.Next() {
+		var i GetFeedFollowTagsForUserRow
+		if err := rows.Scan(&i.FeedFollowID, &i.TagName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedFollowTags = `-- name: ListFeedFollowTags :many
+SELECT feed_follow_id, tag_id, created_at FROM feed_follow_tags
+`
+
+func (q *Queries) ListFeedFollowTags(ctx context.Context) ([]FeedFollowTag, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFollowTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FeedFollowTag
+	for rows.Next() {
+		var i FeedFollowTag
+		if err := rows.Scan(&i.FeedFollowID, &i.TagID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT id, created_at, updated_at, user_id, name FROM tags
+ORDER BY created_at
+`
+
+func (q *Queries) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeFeedFollowTag = `-- name: RemoveFeedFollowTag :execrows
+DELETE FROM feed_follow_tags
+USING tags
+WHERE feed_follow_tags.tag_id = tags.id
+AND feed_follow_tags.feed_follow_id = $1
+AND tags.name = $2
+`
+
+type RemoveFeedFollowTagParams struct {
+	FeedFollowID uuid.UUID `json:"feed_follow_id"`
+	Name         string    `json:"name"`
+}
+
+func (q *Queries) RemoveFeedFollowTag(ctx context.Context, arg RemoveFeedFollowTagParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeFeedFollowTag, arg.FeedFollowID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resetTags = `-- name: ResetTags :exec
+DELETE FROM tags
+`
+
+func (q *Queries) ResetTags(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetTags)
+	return err
+}
@@ -0,0 +1,111 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: user_post_state.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const listUserPostStates = `-- name: ListUserPostStates :many
+SELECT user_id, post_id, created_at, updated_at, read_at FROM user_post_state
+`
+
+func (q *Queries) ListUserPostStates(ctx context.Context) ([]UserPostState, error) {
+	rows, err := q.db.QueryContext(ctx, listUserPostStates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserPostState
+	for rows.Next() {
+		var i UserPostState
+		if err := rows.Scan(
+			&i.UserID,
+			&i.PostID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllPostsRead = `-- name: MarkAllPostsRead :execrows
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+SELECT ff.user_id, p.id, now(), now(), now()
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = $1
+AND ($2::text IS NULL OR f.url = $2)
+AND ($3::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < $3)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = now(),
+    updated_at = now()
+WHERE user_post_state.read_at IS NULL
+`
+
+type MarkAllPostsReadParams struct {
+	UserID  uuid.UUID      `json:"user_id"`
+	FeedUrl sql.NullString `json:"feed_url"`
+	Before  sql.NullTime   `json:"before"`
+}
+
+func (q *Queries) MarkAllPostsRead(ctx context.Context, arg MarkAllPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markAllPostsRead, arg.UserID, arg.FeedUrl, arg.Before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostRead = `-- name: MarkPostRead :exec
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+VALUES ($1, $2, now(), now(), now())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = now(),
+    updated_at = now()
+`
+
+type MarkPostReadParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	PostID uuid.UUID `json:"post_id"`
+}
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg MarkPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.PostID)
+	return err
+}
+
+const markPostUnread = `-- name: MarkPostUnread :exec
+UPDATE user_post_state
+SET read_at = NULL,
+    updated_at = now()
+WHERE user_id = $1
+AND post_id = $2
+`
+
+type MarkPostUnreadParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	PostID uuid.UUID `json:"post_id"`
+}
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostUnread, arg.UserID, arg.PostID)
+	return err
+}
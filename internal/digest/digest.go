@@ -0,0 +1,218 @@
+// Package digest renders the periodic emails that summarize a user's unread
+// posts and sends them over SMTP.
+package digest
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"regexp"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"gator/internal/database"
+)
+
+// Digest frequencies stored in digest_subscriptions.frequency.
+const (
+	Daily  = "daily"
+	Weekly = "weekly"
+)
+
+// Frequencies lists the accepted frequencies.
+var Frequencies = []string{Daily, Weekly}
+
+// Period returns how long a digest with the given frequency covers.
+func Period(frequency string) time.Duration {
+	if frequency == Weekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// excerptLen is the longest post description, in characters, a digest
+// includes.
+const excerptLen = 280
+
+// Digest is the content of one email.
+type Digest struct {
+	User      string
+	Frequency string
+	Since     time.Time
+	Until     time.Time
+	Feeds     []Feed
+}
+
+// Feed is a feed's section of a digest.
+type Feed struct {
+	Name  string
+	URL   string
+	Posts []Post
+}
+
+type Post struct {
+	Title     string
+	URL       string
+	Author    string
+	Published time.Time
+	Excerpt   string
+}
+
+// New groups posts into a digest by feed, newest first within each feed.
+func New(user, frequency string, since, until time.Time, posts []database.GetDigestPostsRow) Digest {
+	posts = slices.Clone(posts)
+	slices.SortFunc(posts, func(a, b database.GetDigestPostsRow) int {
+		if c := cmp.Or(strings.Compare(a.FeedName, b.FeedName), strings.Compare(a.FeedUrl, b.FeedUrl)); c != 0 {
+			return c
+		}
+		if c := postTime(b).Compare(postTime(a)); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	d := Digest{User: user, Frequency: frequency, Since: since, Until: until}
+	for _, p := range posts {
+		if len(d.Feeds) == 0 || d.Feeds[len(d.Feeds)-1].URL != p.FeedUrl {
+			d.Feeds = append(d.Feeds, Feed{Name: p.FeedName, URL: p.FeedUrl})
+		}
+		post := Post{
+			Title:     p.Title,
+			URL:       p.Url,
+			Author:    p.Author.String,
+			Published: postTime(p),
+			Excerpt:   excerpt(p.Description.String),
+		}
+		if post.Title == "" {
+			post.Title = post.URL
+		}
+		feed := &d.Feeds[len(d.Feeds)-1]
+		feed.Posts = append(feed.Posts, post)
+	}
+	return d
+}
+
+// postTime is when p was published, or fetched if it has no date.
+func postTime(p database.GetDigestPostsRow) time.Time {
+	if p.PublishedAt.Valid {
+		return p.PublishedAt.Time
+	}
+	return p.CreatedAt
+}
+
+// PostCount returns the number of posts across all feeds.
+func (d Digest) PostCount() int {
+	n := 0
+	for _, f := range d.Feeds {
+		n += len(f.Posts)
+	}
+	return n
+}
+
+// Message is a rendered digest.
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+var funcs = map[string]any{
+	"date":   func(t time.Time) string { return t.Format("Jan 2, 2006") },
+	"plural": plural,
+}
+
+var textTemplate = texttemplate.Must(texttemplate.New("text").Funcs(funcs).Parse(
+	`Hi {{.User}},
+
+Your {{.Frequency}} gator digest: {{plural .PostCount "unread post"}} since {{date .Since}}.
+{{range .Feeds}}
+== {{.Name}} ==
+{{range .Posts}}
+* {{.Title}} ({{date .Published}})
+  {{.URL}}
+{{- if .Author}}
+  by {{.Author}}{{end}}
+{{- if .Excerpt}}
+  {{.Excerpt}}{{end}}
+{{end}}{{end}}
+You are receiving this because you subscribed with "gator digest {{.Frequency}}".
+Run "gator digest off" to stop.
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; max-width: 40em;">
+<p>Hi {{.User}},</p>
+<p>Your {{.Frequency}} gator digest: {{plural .PostCount "unread post"}} since {{date .Since}}.</p>
+{{range .Feeds}}
+<h2><a href="{{.URL}}">{{.Name}}</a></h2>
+<ul>
+{{- range .Posts}}
+<li>
+<a href="{{.URL}}">{{.Title}}</a> <small>{{date .Published}}{{if .Author}} by {{.Author}}{{end}}</small>
+{{- if .Excerpt}}
+<p>{{.Excerpt}}</p>
+{{- end}}
+</li>
+{{- end}}
+</ul>
+{{end}}
+<p><small>You are receiving this because you subscribed with <code>gator digest {{.Frequency}}</code>.
+Run <code>gator digest off</code> to stop.</small></p>
+</body>
+</html>
+`))
+
+// Render produces the subject and both bodies of the digest email.
+func Render(d Digest) (Message, error) {
+	msg := Message{
+		Subject: fmt.Sprintf("gator digest: %s from %s",
+			plural(d.PostCount(), "unread post"), plural(len(d.Feeds), "feed")),
+	}
+
+	var buf bytes.Buffer
+	if err := textTemplate.Execute(&buf, d); err != nil {
+		return Message{}, fmt.Errorf("failed to render text digest: %w", err)
+	}
+	msg.Text = buf.String()
+
+	buf.Reset()
+	if err := htmlTemplate.Execute(&buf, d); err != nil {
+		return Message{}, fmt.Errorf("failed to render HTML digest: %w", err)
+	}
+	msg.HTML = buf.String()
+	return msg, nil
+}
+
+var (
+	anyTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+	scriptTag = regexp.MustCompile(`(?is)<\s*(script|style)\b.*?<\s*/\s*(script|style)\s*>`)
+)
+
+// excerpt turns a feed description, which is usually HTML, into a single
+// line of plain text no longer than excerptLen characters.
+func excerpt(description string) string {
+	s := scriptTag.ReplaceAllString(description, "")
+	s = anyTag.ReplaceAllString(s, " ")
+	s = strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+	if utf8.RuneCountInString(s) <= excerptLen {
+		return s
+	}
+	r := []rune(s)[:excerptLen]
+	if i := strings.LastIndexByte(string(r), ' '); i > 0 {
+		return string(r)[:i] + "…"
+	}
+	return string(r) + "…"
+}
@@ -0,0 +1,170 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"gator/internal/config"
+)
+
+// sendTimeout bounds a whole SMTP conversation.
+const sendTimeout = time.Minute
+
+// Compose builds a multipart/alternative email holding the plain text and
+// HTML versions of msg.
+func Compose(from, to string, msg Message, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(part.content, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", messageID(from)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()})},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	domain := "gator.localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndexByte(addr.Address, '@'); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// Send delivers msg to the address to through the configured SMTP server.
+func Send(ctx context.Context, cfg config.SMTPConfig, to string, msg Message) error {
+	cfg = cfg.WithDefaults()
+	if cfg.Host == "" {
+		return errors.New("no SMTP server configured: set smtp.host in ~/.gatorconfig.json")
+	}
+	if cfg.From == "" {
+		return errors.New("no sender configured: set smtp.from in ~/.gatorconfig.json")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid smtp.from address: %w", err)
+	}
+	data, err := Compose(cfg.From, to, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	c, err := dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if cfg.Security == config.SMTPStartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return fmt.Errorf("server rejected sender: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("server rejected recipient: %w", err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return c.Quit()
+}
+
+func dial(ctx context.Context, cfg config.SMTPConfig) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch cfg.Security {
+	case config.SMTPTLS:
+		d := tls.Dialer{Config: &tls.Config{ServerName: cfg.Host}}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	case config.SMTPStartTLS, config.SMTPNone:
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	default:
+		return nil, fmt.Errorf("unknown smtp.security %q: use starttls, tls or none", cfg.Security)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,86 @@
+// Package events is an in-process bus that tells listeners, such as the
+// Server-Sent Events stream, about posts as soon as the aggregator stores
+// them.
+//
+// Delivery is best effort: a subscriber that falls too far behind is
+// dropped rather than slowing down the aggregator, and must catch up from
+// the database.
+package events
+
+import (
+	"sync"
+
+	"gator/internal/database"
+)
+
+// subscriberBuffer is how many events a subscriber may have queued before
+// it is dropped.
+const subscriberBuffer = 256
+
+// PostCreated reports a post inserted by the aggregator. It is published
+// only after the transaction that inserted it has committed.
+type PostCreated struct {
+	Post database.Post
+	Feed database.Feed
+}
+
+// Bus fans out events to every current subscriber. The zero value is not
+// usable; create one with NewBus. A nil *Bus discards everything published
+// to it, so callers don't need to check whether anyone is listening.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives events published after it was created. C is closed
+// when the subscription is closed or dropped for falling behind.
+type Subscription struct {
+	C   <-chan PostCreated
+	c   chan PostCreated
+	bus *Bus
+}
+
+func (b *Bus) Subscribe() *Subscription {
+	c := make(chan PostCreated, subscriberBuffer)
+	sub := &Subscription{C: c, c: c, bus: b}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Close stops delivery to the subscription. It is safe to call more than
+// once.
+func (sub *Subscription) Close() {
+	sub.bus.mu.Lock()
+	defer sub.bus.mu.Unlock()
+	sub.bus.drop(sub)
+}
+
+// Publish sends e to every subscriber without blocking.
+func (b *Bus) Publish(e PostCreated) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		select {
+		case sub.c <- e:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// drop removes and closes sub. Callers must hold b.mu.
+func (b *Bus) drop(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.c)
+	}
+}
@@ -0,0 +1,42 @@
+// Package logging builds the slog logger gator writes diagnostics with.
+// Diagnostics always go to stderr so that stdout carries only command
+// output.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Handler formats.
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// Formats lists the accepted formats.
+var Formats = []string{Text, JSON}
+
+// New returns a logger writing records at or above level to w. level is
+// debug, info, warn or error and format is one of Formats. Empty values
+// mean info and text.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", level)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "", Text:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case JSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be %s", format, strings.Join(Formats, " or "))
+	}
+}
@@ -0,0 +1,72 @@
+// Package metrics holds the Prometheus metrics agg exports and serves them
+// for scraping.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// FetchError is the status label of fetches that got no HTTP response.
+const FetchError = "error"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// FeedFetches counts feed fetches by HTTP status code, or FetchError.
+	FeedFetches = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gator_feed_fetches_total",
+		Help: "Feed fetches by HTTP status code, or \"error\" when no response was received.",
+	}, []string{"status"}))
+
+	FetchDuration = register(prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "gator_feed_fetch_duration_seconds",
+		Help:    "Time taken to download a feed.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}))
+
+	FetchedBytes = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_feed_fetch_bytes_total",
+		Help: "Bytes of feed bodies downloaded.",
+	}))
+
+	ParseErrors = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_feed_parse_errors_total",
+		Help: "Downloaded feeds that could not be parsed.",
+	}))
+
+	PostsInserted = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_inserted_total",
+		Help: "New posts stored.",
+	}))
+
+	PostsDuplicate = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "gator_posts_duplicate_total",
+		Help: "Fetched posts skipped because their URL was already stored.",
+	}))
+
+	FeedsDue = register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "gator_feeds_due",
+		Help: "Feeds never fetched or not fetched within the due interval, as of the last fetch.",
+	}))
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+func register[C prometheus.Collector](c C) C {
+	registry.MustRegister(c)
+	return c
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
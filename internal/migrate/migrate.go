@@ -0,0 +1,387 @@
+// Package migrate applies the goose-formatted migrations in sql/schema and
+// records them in goose's own version table, so databases migrated with the
+// goose CLI and databases migrated by gator stay interchangeable.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const versionTable = "goose_db_version"
+
+// Dialect selects the SQL used to maintain the version table.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt sql.NullTime
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// ErrSchemaBehind is returned by Check when the database has not been
+// migrated to the latest embedded version.
+type ErrSchemaBehind struct {
+	Current int64
+	Latest  int64
+}
+
+func (e *ErrSchemaBehind) Error() string {
+	return fmt.Sprintf("database schema is at version %d but gator needs version %d; run `gator migrate up`", e.Current, e.Latest)
+}
+
+// ErrSchemaAhead is returned by Check when the database has migrations
+// applied that this build of gator doesn't know about.
+type ErrSchemaAhead struct {
+	Current int64
+	Latest  int64
+}
+
+func (e *ErrSchemaAhead) Error() string {
+	return fmt.Sprintf("database schema is at version %d but this gator only knows up to version %d; upgrade gator", e.Current, e.Latest)
+}
+
+func New(db *sql.DB, dialect Dialect, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Load parses every NNN_name.sql file in fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".sql")
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: file name must start with a version number", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		up, down, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", file, err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parse splits a goose migration into its Up and Down sections. Other goose
+// annotations are dropped since each section is executed as a single batch.
+func parse(contents string) (string, string, error) {
+	var up, down strings.Builder
+	var current *strings.Builder
+
+	for _, line := range strings.Split(contents, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "-- +goose") {
+			switch strings.TrimSpace(strings.TrimPrefix(trimmed, "-- +goose")) {
+			case "Up":
+				current = &up
+			case "Down":
+				current = &down
+			}
+			continue
+		}
+		if current == nil {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return "", "", errors.New("statement before -- +goose Up")
+			}
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+
+	if strings.TrimSpace(up.String()) == "" {
+		return "", "", errors.New("missing -- +goose Up section")
+	}
+	return up.String(), down.String(), nil
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	ddl := `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+    id SERIAL PRIMARY KEY,
+    version_id BIGINT NOT NULL,
+    is_applied BOOLEAN NOT NULL,
+    tstamp TIMESTAMP NULL DEFAULT now()
+)`
+	if m.dialect == SQLite {
+		ddl = `CREATE TABLE IF NOT EXISTS ` + versionTable + ` (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version_id INTEGER NOT NULL,
+    is_applied INTEGER NOT NULL,
+    tstamp TIMESTAMP DEFAULT (datetime('now'))
+)`
+	}
+	_, err := m.db.ExecContext(ctx, ddl)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", versionTable, err)
+	}
+
+	var count int
+	if err := m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+versionTable).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read %s: %w", versionTable, err)
+	}
+	if count == 0 {
+		_, err := m.db.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES (0, true)`)
+		if err != nil {
+			return fmt.Errorf("failed to initialize %s: %w", versionTable, err)
+		}
+	}
+	return nil
+}
+
+// applied returns the time each applied version was recorded. As in goose,
+// the most recent row for a version decides whether it is applied.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version_id, is_applied, tstamp FROM `+versionTable+` ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", versionTable, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	seen := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version   int64
+			isApplied bool
+			tstamp    sql.NullTime
+		)
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if isApplied && version > 0 {
+			applied[version] = tstamp.Time
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Current returns the highest applied version, or 0 for an empty database.
+func (m *Migrator) Current(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return highest(applied), nil
+}
+
+// Check returns an *ErrSchemaBehind if any embedded migration is pending,
+// or an *ErrSchemaAhead if the database was migrated by a newer gator.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if current := highest(applied); current > m.Latest() {
+		return &ErrSchemaAhead{Current: current, Latest: m.Latest()}
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			return &ErrSchemaBehind{Current: highest(applied), Latest: m.Latest()}
+		}
+	}
+	return nil
+}
+
+func highest(applied map[int64]time.Time) int64 {
+	var version int64
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i].Migration = migration
+		if at, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = sql.NullTime{Time: at, Valid: true}
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok {
+			return migration, m.rollback(ctx, migration)
+		}
+	}
+	return Migration{}, errors.New("no migrations to roll back")
+}
+
+// To migrates up or down until version is the highest applied migration and
+// returns the migrations it applied or rolled back, in order.
+func (m *Migrator) To(ctx context.Context, version int64) ([]Migration, error) {
+	if version != 0 && !m.has(version) {
+		return nil, fmt.Errorf("unknown migration version %d", version)
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+		if err := m.rollback(ctx, migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+		if err := m.apply(ctx, migration); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) has(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	return m.run(ctx, migration, migration.Up, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES (`+m.placeholder()+`, true)`)
+}
+
+func (m *Migrator) rollback(ctx context.Context, migration Migration) error {
+	return m.run(ctx, migration, migration.Down, `DELETE FROM `+versionTable+` WHERE version_id = `+m.placeholder())
+}
+
+func (m *Migrator) placeholder() string {
+	if m.dialect == SQLite {
+		return "?"
+	}
+	return "$1"
+}
+
+func (m *Migrator) run(ctx context.Context, migration Migration, statements, record string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(statements) != "" {
+		if _, err := tx.ExecContext(ctx, statements); err != nil {
+			return fmt.Errorf("migration %s failed: %w", migration.Name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,63 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func migrations(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range names {
+		fsys[name+".sql"] = &fstest.MapFile{Data: []byte(
+			"-- +goose Up\nCREATE TABLE t" + name[:3] + " (id INTEGER);\n\n-- +goose Down\nDROP TABLE t" + name[:3] + ";\n",
+		)}
+	}
+	return fsys
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "gator.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	newMigrator := func(names ...string) *Migrator {
+		t.Helper()
+		m, err := New(db, SQLite, migrations(names...))
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		return m
+	}
+	current := newMigrator("001_one", "002_two")
+
+	var behind *ErrSchemaBehind
+	if err := current.Check(ctx); !errors.As(err, &behind) || behind.Current != 0 || behind.Latest != 2 {
+		t.Fatalf("Check before migrating: got %v, want schema behind at 0 of 2", err)
+	}
+	if _, err := current.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if err := current.Check(ctx); err != nil {
+		t.Fatalf("Check after migrating: %v", err)
+	}
+
+	// An older gator doesn't know about migration 2.
+	var ahead *ErrSchemaAhead
+	if err := newMigrator("001_one").Check(ctx); !errors.As(err, &ahead) || ahead.Current != 2 || ahead.Latest != 1 {
+		t.Errorf("Check with an older gator: got %v, want schema ahead at 2 of 1", err)
+	}
+
+	// A newer gator has a migration to apply.
+	if err := newMigrator("001_one", "002_two", "003_three").Check(ctx); !errors.As(err, &behind) || behind.Current != 2 || behind.Latest != 3 {
+		t.Errorf("Check with a newer gator: got %v, want schema behind at 2 of 3", err)
+	}
+}
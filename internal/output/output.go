@@ -0,0 +1,201 @@
+// Package output renders rows of command output as a table, JSON, JSON
+// lines, CSV or a user-supplied text/template.
+//
+// Rows are slices of structs. Each exported field with a json tag becomes a
+// column named after the tag; fields tagged "-" are skipped.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+const (
+	Table = "table"
+	JSON  = "json"
+	JSONL = "jsonl"
+	CSV   = "csv"
+)
+
+// Formats lists the named formats accepted by New.
+var Formats = []string{Table, JSON, JSONL, CSV}
+
+type Renderer struct {
+	w      io.Writer
+	notes  io.Writer
+	format string
+	tmpl   *template.Template
+}
+
+// New returns a Renderer writing rows to w in the given format, which is
+// one of Formats or a text/template executed once per row. Notes go to w
+// for tables and to notes otherwise, so they never corrupt
+// machine-readable output.
+func New(w, notes io.Writer, format string) (*Renderer, error) {
+	r := &Renderer{w: w, notes: notes, format: format}
+	switch format {
+	case Table:
+		r.notes = w
+		return r, nil
+	case JSON, JSONL, CSV:
+		return r, nil
+	}
+	if !strings.Contains(format, "{{") {
+		return nil, fmt.Errorf("unknown output format %q: must be %s or a Go template", format, strings.Join(Formats, ", "))
+	}
+	tmpl, err := template.New("output").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	r.tmpl = tmpl
+	return r, nil
+}
+
+// Notef prints an informational message, such as a pagination hint, that
+// is not part of the rows.
+func (r *Renderer) Notef(format string, args ...any) {
+	fmt.Fprintf(r.notes, format+"\n", args...)
+}
+
+// Render writes rows, which must be a slice of structs.
+func (r *Renderer) Render(rows any) error {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Struct {
+		return errors.New("output: rows must be a slice of structs")
+	}
+
+	switch {
+	case r.tmpl != nil:
+		return r.renderTemplate(v)
+	case r.format == JSON:
+		if v.IsNil() {
+			v = reflect.MakeSlice(v.Type(), 0, 0)
+		}
+		enc := json.NewEncoder(r.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v.Interface())
+	case r.format == JSONL:
+		enc := json.NewEncoder(r.w)
+		for i := 0; i < v.Len(); i++ {
+			if err := enc.Encode(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case r.format == CSV:
+		return r.renderCSV(v)
+	default:
+		return r.renderTable(v)
+	}
+}
+
+func (r *Renderer) renderTemplate(v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := r.tmpl.Execute(r.w, v.Index(i).Interface()); err != nil {
+			return fmt.Errorf("failed to execute output template: %w", err)
+		}
+		fmt.Fprintln(r.w)
+	}
+	return nil
+}
+
+func (r *Renderer) renderCSV(v reflect.Value) error {
+	cols := columns(v.Type().Elem())
+	cw := csv.NewWriter(r.w)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := cw.Write(cells(v.Index(i), cols)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r *Renderer) renderTable(v reflect.Value) error {
+	if v.Len() == 0 {
+		return nil
+	}
+	cols := columns(v.Type().Elem())
+	tw := tabwriter.NewWriter(r.w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = strings.ToUpper(strings.ReplaceAll(c.name, "_", " "))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for i := 0; i < v.Len(); i++ {
+		row := cells(v.Index(i), cols)
+		for j, cell := range row {
+			// Tabs and newlines would break the column layout.
+			row[j] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+type column struct {
+	name  string
+	index int
+}
+
+func columns(t reflect.Type) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		cols = append(cols, column{name: name, index: i})
+	}
+	return cols
+}
+
+func cells(row reflect.Value, cols []column) []string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = format(row.Field(c.index))
+	}
+	return out
+}
+
+// format converts a field to the text shown in tables and CSV.
+func format(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	case []string:
+		return strings.Join(x, ",")
+	case fmt.Stringer:
+		return x.String()
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+)
+
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyTop
+	keyBottom
+	keyTab
+	keyEnter
+	keyToggleRead
+	keyToggleStar
+	keyOpen
+	keyToggleAll
+	keyRefresh
+	keyRedraw
+	keyQuit
+)
+
+// runeKeys maps single-byte input to keys. Vi-style movement sits next to
+// the arrow keys.
+var runeKeys = map[byte]key{
+	'k':  keyUp,
+	'j':  keyDown,
+	'h':  keyLeft,
+	'l':  keyRight,
+	'g':  keyTop,
+	'G':  keyBottom,
+	'\t': keyTab,
+	'\r': keyEnter,
+	'\n': keyEnter,
+	' ':  keyPageDown,
+	'r':  keyToggleRead,
+	's':  keyToggleStar,
+	'o':  keyOpen,
+	'u':  keyToggleAll,
+	'R':  keyRefresh,
+	0x0c: keyRedraw, // ctrl-L
+	'q':  keyQuit,
+	0x03: keyQuit, // ctrl-C
+}
+
+// escapeKeys maps the bytes following ESC [ to keys.
+var escapeKeys = map[string]key{
+	"A":  keyUp,
+	"B":  keyDown,
+	"C":  keyRight,
+	"D":  keyLeft,
+	"H":  keyTop,
+	"F":  keyBottom,
+	"5~": keyPageUp,
+	"6~": keyPageDown,
+}
+
+// readKeys decodes key presses from r until it fails, then closes the
+// returned channel. Unrecognised input is dropped.
+func readKeys(r io.Reader) <-chan key {
+	keys := make(chan key)
+	go func() {
+		defer close(keys)
+		br := bufio.NewReader(r)
+		for {
+			b, err := br.ReadByte()
+			if err != nil {
+				return
+			}
+			k := runeKeys[b]
+			if b == 0x1b && br.Buffered() > 0 {
+				k = readEscape(br)
+			}
+			if k != keyNone {
+				keys <- k
+			}
+		}
+	}()
+	return keys
+}
+
+// readEscape reads the rest of a CSI sequence such as ESC [ 5 ~.
+func readEscape(br *bufio.Reader) key {
+	if b, err := br.ReadByte(); err != nil || (b != '[' && b != 'O') {
+		return keyNone
+	}
+	var seq []byte
+	for br.Buffered() > 0 {
+		b, err := br.ReadByte()
+		if err != nil {
+			return keyNone
+		}
+		seq = append(seq, b)
+		// Parameters are digits and semicolons; anything else ends the
+		// sequence.
+		if (b < '0' || b > '9') && b != ';' {
+			break
+		}
+	}
+	return escapeKeys[string(seq)]
+}
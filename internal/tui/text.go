@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	blockTag  = regexp.MustCompile(`(?i)<\s*/?\s*(p|div|br|h[1-6]|blockquote|pre|tr|ul|ol)\b[^>]*>`)
+	listItem  = regexp.MustCompile(`(?i)<\s*li\b[^>]*>`)
+	scriptTag = regexp.MustCompile(`(?is)<\s*(script|style)\b.*?<\s*/\s*(script|style)\s*>`)
+	anyTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText turns the HTML found in feed descriptions into plain text,
+// keeping paragraph breaks and list bullets.
+func htmlToText(s string) string {
+	s = scriptTag.ReplaceAllString(s, "")
+	s = blockTag.ReplaceAllString(s, "\n\n")
+	s = listItem.ReplaceAllString(s, "\n• ")
+	s = anyTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	s = strings.Join(lines, "\n")
+	s = blankRuns.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// wrap breaks text into lines of at most width runes, splitting on spaces
+// where possible.
+func wrap(text string, width int) []string {
+	if width < 1 {
+		return nil
+	}
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				r := []rune(word)
+				lines = append(lines, string(r[:width]))
+				word = string(r[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit truncates or pads s to exactly width runes. Control characters are
+// replaced so feed content can't send escape sequences to the terminal.
+func fit(s string, width int) string {
+	if width < 1 {
+		return ""
+	}
+	r := []rune(strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) {
+			return ' '
+		}
+		return c
+	}, s))
+	if len(r) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return string(r) + strings.Repeat(" ", width-len(r))
+}
@@ -0,0 +1,348 @@
+// Package tui implements a full-screen terminal reader: followed feeds on
+// the left, their posts in the middle and the selected post on the right.
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+// postLimit caps how many posts are loaded for the selected feed.
+const postLimit = 500
+
+// far is a move large enough to reach either end of any list.
+const far = 1 << 30
+
+type pane int
+
+const (
+	feedsPane pane = iota
+	postsPane
+	previewPane
+)
+
+// feedItem is a row in the feeds pane. The first row, with an empty URL,
+// shows posts from every followed feed.
+type feedItem struct {
+	id     uuid.UUID
+	name   string
+	url    string
+	unread int64
+}
+
+type model struct {
+	ctx  context.Context
+	db   database.Querier
+	user database.User
+
+	feeds   []feedItem
+	posts   []database.BrowsePostsForUserRow
+	starred map[uuid.UUID]bool
+
+	focus   pane
+	feedIdx int
+	postIdx int
+	feedTop int
+	postTop int
+	scroll  int
+	showAll bool
+	status  string
+	clear   bool
+}
+
+// Run shows the reader for user until they quit. stdin and stdout must be
+// a terminal.
+func Run(ctx context.Context, db database.Querier, user database.User) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errors.New("tui needs an interactive terminal")
+	}
+
+	m := &model{ctx: ctx, db: db, user: user}
+	if err := m.loadFeeds(); err != nil {
+		return err
+	}
+	if err := m.loadStarred(); err != nil {
+		return err
+	}
+	if err := m.loadPosts(); err != nil {
+		return err
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(fd, oldState)
+
+	// Switch to the alternate screen and hide the cursor; undo both on exit.
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	keys := readKeys(os.Stdin)
+	for {
+		if err := m.draw(os.Stdout); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				return nil
+			}
+			m.status = ""
+			if err := m.handle(k); err != nil {
+				m.status = err.Error()
+			}
+		}
+	}
+}
+
+func (m *model) handle(k key) error {
+	switch k {
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case keyPageUp:
+		m.move(-m.pageSize())
+	case keyPageDown:
+		m.move(m.pageSize())
+	case keyTop:
+		m.move(-far)
+	case keyBottom:
+		m.move(far)
+	case keyLeft:
+		if m.focus > feedsPane {
+			m.focus--
+		}
+	case keyRight, keyEnter:
+		if m.focus < previewPane {
+			m.focus++
+		}
+		if m.focus == previewPane && len(m.posts) > 0 && !m.posts[m.postIdx].ReadAt.Valid {
+			return m.toggleRead()
+		}
+	case keyTab:
+		m.focus = (m.focus + 1) % 3
+	case keyToggleRead:
+		return m.toggleRead()
+	case keyToggleStar:
+		return m.toggleStar()
+	case keyOpen:
+		return m.open()
+	case keyToggleAll:
+		m.showAll = !m.showAll
+		return m.loadPosts()
+	case keyRefresh:
+		if err := m.loadFeeds(); err != nil {
+			return err
+		}
+		if err := m.loadStarred(); err != nil {
+			return err
+		}
+		return m.loadPosts()
+	case keyRedraw:
+		m.clear = true
+	}
+	return nil
+}
+
+// move moves the selection in the focused pane by delta rows, or scrolls
+// the preview.
+func (m *model) move(delta int) {
+	switch m.focus {
+	case feedsPane:
+		idx := clamp(m.feedIdx+delta, 0, len(m.feeds)-1)
+		if idx != m.feedIdx {
+			m.feedIdx = idx
+			if err := m.loadPosts(); err != nil {
+				m.status = err.Error()
+			}
+		}
+	case postsPane:
+		m.postIdx = clamp(m.postIdx+delta, 0, len(m.posts)-1)
+		m.scroll = 0
+	case previewPane:
+		m.scroll = max(m.scroll+delta, 0)
+	}
+}
+
+func (m *model) loadFeeds() error {
+	follows, err := m.db.GetFeedFollowsForUser(m.ctx, m.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get feed follows: %w", err)
+	}
+	m.feeds = []feedItem{{name: "All feeds"}}
+	for _, ff := range follows {
+		m.feeds = append(m.feeds, feedItem{id: ff.FeedID, name: ff.FeedName, url: ff.FeedUrl, unread: ff.UnreadCount})
+		m.feeds[0].unread += ff.UnreadCount
+	}
+	m.feedIdx = clamp(m.feedIdx, 0, len(m.feeds)-1)
+	return nil
+}
+
+func (m *model) loadStarred() error {
+	total, err := m.db.CountStarredPostsForUser(m.ctx, m.user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count saved posts: %w", err)
+	}
+	starred, err := m.db.GetStarredPostsForUser(m.ctx, database.GetStarredPostsForUserParams{
+		UserID: m.user.ID,
+		Limit:  int32(total),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get saved posts: %w", err)
+	}
+	m.starred = make(map[uuid.UUID]bool, len(starred))
+	for _, sp := range starred {
+		if sp.PostID.Valid {
+			m.starred[sp.PostID.UUID] = true
+		}
+	}
+	return nil
+}
+
+func (m *model) loadPosts() error {
+	feed := m.feeds[m.feedIdx]
+	posts, err := m.db.BrowsePostsForUser(m.ctx, database.BrowsePostsForUserParams{
+		UserID:     m.user.ID,
+		UnreadOnly: !m.showAll,
+		FeedUrl:    sql.NullString{String: feed.url, Valid: feed.url != ""},
+		Sort:       "published",
+		Limit:      postLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+	m.posts = posts
+	m.postIdx, m.postTop, m.scroll = 0, 0, 0
+	return nil
+}
+
+// toggleRead flips the selected post between read and unread. The post
+// stays in the list until the next reload so the selection doesn't jump.
+func (m *model) toggleRead() error {
+	if len(m.posts) == 0 {
+		return nil
+	}
+	post := &m.posts[m.postIdx]
+	delta := int64(-1)
+	if post.ReadAt.Valid {
+		err := m.db.MarkPostUnread(m.ctx, database.MarkPostUnreadParams{UserID: m.user.ID, PostID: post.ID})
+		if err != nil {
+			return fmt.Errorf("failed to mark post unread: %w", err)
+		}
+		post.ReadAt = sql.NullTime{}
+		delta = 1
+	} else {
+		err := m.db.MarkPostRead(m.ctx, database.MarkPostReadParams{UserID: m.user.ID, PostID: post.ID})
+		if err != nil {
+			return fmt.Errorf("failed to mark post read: %w", err)
+		}
+		post.ReadAt = sql.NullTime{Valid: true}
+	}
+	for i := range m.feeds {
+		if i == 0 || m.feeds[i].id == post.FeedID {
+			m.feeds[i].unread += delta
+		}
+	}
+	return nil
+}
+
+func (m *model) toggleStar() error {
+	if len(m.posts) == 0 {
+		return nil
+	}
+	post := m.posts[m.postIdx]
+	if m.starred[post.ID] {
+		_, err := m.db.UnstarPost(m.ctx, database.UnstarPostParams{UserID: m.user.ID, Ref: post.ID.String()})
+		if err != nil {
+			return fmt.Errorf("failed to unstar post: %w", err)
+		}
+		delete(m.starred, post.ID)
+		m.status = "Unstarred " + post.Title
+		return nil
+	}
+	_, err := m.db.StarPost(m.ctx, database.StarPostParams{ID: uuid.New(), UserID: m.user.ID, PostID: post.ID})
+	if err != nil {
+		return fmt.Errorf("failed to star post: %w", err)
+	}
+	m.starred[post.ID] = true
+	m.status = "Starred " + post.Title
+	return nil
+}
+
+// open shows the selected post in the system browser and marks it read.
+func (m *model) open() error {
+	if len(m.posts) == 0 {
+		return nil
+	}
+	post := m.posts[m.postIdx]
+	if err := openBrowser(post.Url); err != nil {
+		return err
+	}
+	m.status = "Opened " + post.Url
+	if !post.ReadAt.Valid {
+		return m.toggleRead()
+	}
+	return nil
+}
+
+// openBrowser opens a post's link. Links come from feeds, so anything but an
+// absolute http or https URL is refused rather than handed to the system
+// opener, which would also run file: URLs and other handlers.
+func openBrowser(rawURL string) error {
+	link, err := browserURL(rawURL)
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// browserURL returns rawURL normalized if it is an absolute http or https
+// URL with a host.
+func browserURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid link %q", rawURL)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("not opening %q: only http and https links are opened", rawURL)
+	}
+	return u.String(), nil
+}
+
+func clamp(v, lo, hi int) int {
+	if hi < lo {
+		return lo
+	}
+	return min(max(v, lo), hi)
+}
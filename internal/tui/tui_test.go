@@ -0,0 +1,27 @@
+package tui
+
+import "testing"
+
+func TestBrowserURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+		ok   bool
+	}{
+		{"https://example.com/post", "https://example.com/post", true},
+		{"HTTP://example.com/a b", "http://example.com/a%20b", true},
+		{"file:///etc/passwd", "", false},
+		{"javascript:alert(1)", "", false},
+		{"smb://host/share", "", false},
+		{"-a https://example.com", "", false},
+		{"//example.com/post", "", false},
+		{"https:///post", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := browserURL(tt.url)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("browserURL(%q) = %q, %v; want %q, ok %v", tt.url, got, err, tt.want, tt.ok)
+		}
+	}
+}
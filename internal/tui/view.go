@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	reverse = "\x1b[7m"
+	bold    = "\x1b[1m"
+	dim     = "\x1b[2m"
+	reset   = "\x1b[0m"
+)
+
+const helpLine = "j/k move  h/l/tab pane  r read  s star  o open  u unread/all  R reload  q quit"
+
+// screenSize returns the terminal size, falling back to 80x24 when it
+// can't be read.
+func screenSize() (width, height int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 20 || height < 5 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// pageSize is how far PgUp and PgDn move: one screen of list rows.
+func (m *model) pageSize() int {
+	_, height := screenSize()
+	return max(height-3, 1)
+}
+
+// draw repaints the whole screen: a title bar, the three panes and a
+// status line.
+func (m *model) draw(w io.Writer) error {
+	width, height := screenSize()
+	rows := height - 2
+
+	feedsW := max(width/5, 10)
+	postsW := max(2*width/5, 10)
+	previewW := max(width-feedsW-postsW-2, 1)
+
+	feeds := m.feedLines(feedsW, rows)
+	posts := m.postLines(postsW, rows)
+	preview := m.previewLines(previewW, rows)
+
+	bw := bufio.NewWriter(w)
+	if m.clear {
+		bw.WriteString("\x1b[2J")
+		m.clear = false
+	}
+	bw.WriteString("\x1b[H")
+
+	mode := "unread"
+	if m.showAll {
+		mode = "all posts"
+	}
+	title := fmt.Sprintf(" gator — %s — %s", m.user.Name, mode)
+	bw.WriteString(reverse + fit(title, width) + reset + "\x1b[K\r\n")
+
+	for i := 0; i < rows; i++ {
+		bw.WriteString(feeds[i] + "│" + posts[i] + "│" + preview[i] + "\x1b[K\r\n")
+	}
+
+	status := m.status
+	if status == "" {
+		status = helpLine
+	}
+	bw.WriteString(dim + fit(status, width) + reset + "\x1b[K")
+	return bw.Flush()
+}
+
+func (m *model) feedLines(width, rows int) []string {
+	m.feedTop = scrollTo(m.feedTop, m.feedIdx, rows)
+	lines := make([]string, rows)
+	for i := range lines {
+		idx := m.feedTop + i
+		if idx >= len(m.feeds) {
+			lines[i] = fit("", width)
+			continue
+		}
+		f := m.feeds[idx]
+		count := ""
+		if f.unread > 0 {
+			count = fmt.Sprintf(" %d", f.unread)
+		}
+		name := fit(f.name, max(width-len(count)-1, 1))
+		lines[i] = m.highlight(fit(" "+name+count, width), feedsPane, idx == m.feedIdx)
+	}
+	return lines
+}
+
+func (m *model) postLines(width, rows int) []string {
+	m.postTop = scrollTo(m.postTop, m.postIdx, rows)
+	lines := make([]string, rows)
+	if len(m.posts) == 0 {
+		lines[0] = fit(" No posts", width)
+		for i := 1; i < rows; i++ {
+			lines[i] = fit("", width)
+		}
+		return lines
+	}
+	for i := range lines {
+		idx := m.postTop + i
+		if idx >= len(m.posts) {
+			lines[i] = fit("", width)
+			continue
+		}
+		p := m.posts[idx]
+		marker := " "
+		if !p.ReadAt.Valid {
+			marker = "•"
+		}
+		if m.starred[p.ID] {
+			marker = "★"
+		}
+		date := "     "
+		if p.PublishedAt.Valid {
+			date = p.PublishedAt.Time.Local().Format("Jan 2")
+		}
+		line := fmt.Sprintf("%s %-6s %s", marker, date, p.Title)
+		lines[i] = m.highlight(fit(line, width), postsPane, idx == m.postIdx)
+	}
+	return lines
+}
+
+func (m *model) previewLines(width, rows int) []string {
+	var content []string
+	if len(m.posts) > 0 {
+		p := m.posts[m.postIdx]
+		for _, l := range wrap(p.Title, width-1) {
+			content = append(content, bold+fit(" "+l, width)+reset)
+		}
+		meta := []string{p.FeedName}
+		if p.Author.Valid && p.Author.String != "" {
+			meta = append(meta, p.Author.String)
+		}
+		if p.PublishedAt.Valid {
+			meta = append(meta, p.PublishedAt.Time.Local().Format(time.DateTime))
+		}
+		content = append(content,
+			dim+fit(" "+strings.Join(meta, " · "), width)+reset,
+			dim+fit(" "+p.Url, width)+reset,
+			fit("", width),
+		)
+		for _, l := range wrap(htmlToText(p.Description.String), width-2) {
+			content = append(content, fit(" "+l, width))
+		}
+	}
+
+	m.scroll = clamp(m.scroll, 0, len(content)-rows)
+	lines := make([]string, rows)
+	for i := range lines {
+		if idx := m.scroll + i; idx < len(content) {
+			lines[i] = content[idx]
+		} else {
+			lines[i] = fit("", width)
+		}
+	}
+	return lines
+}
+
+// highlight shows the selected row in reverse video when its pane has
+// focus, and in bold otherwise.
+func (m *model) highlight(line string, p pane, selected bool) string {
+	switch {
+	case !selected:
+		return line
+	case m.focus == p:
+		return reverse + line + reset
+	default:
+		return bold + line + reset
+	}
+}
+
+// scrollTo returns the first visible row so that selected stays within a
+// window of the given height.
+func scrollTo(top, selected, height int) int {
+	if selected < top {
+		return selected
+	}
+	if selected >= top+height {
+		return selected - height + 1
+	}
+	return top
+}
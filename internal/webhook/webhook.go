@@ -0,0 +1,234 @@
+// Package webhook builds, signs and sends the requests gator makes to
+// users' webhook endpoints when new posts arrive.
+//
+// Every request carries these headers:
+//
+//	X-Gator-Event: post.created
+//	X-Gator-Delivery: <delivery ID, the same for every retry>
+//	X-Gator-Timestamp: <Unix time the request was sent>
+//	X-Gator-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// The HMAC key is the webhook's secret. Receivers should recompute the
+// signature and reject old timestamps to guard against replays.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// Payload formats. JSON is gator's own format; Slack and Discord produce
+// bodies those services' incoming webhooks accept.
+const (
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+// Formats lists the accepted formats.
+var Formats = []string{FormatJSON, FormatSlack, FormatDiscord}
+
+// Delivery states stored in webhook_deliveries.status.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is
+	// marked failed.
+	MaxAttempts = 8
+	// firstRetry is the delay before the second attempt. Each later retry
+	// waits twice as long as the one before.
+	firstRetry = time.Minute
+	timeout    = 10 * time.Second
+)
+
+// NewSecret returns a random secret for signing a new webhook's requests.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Matches reports whether p passes w's keyword filter, which is matched
+// case-insensitively against the title and description. The feed and tag
+// filters are applied by the GetWebhooksForFeed query.
+func Matches(w database.Webhook, p database.Post) bool {
+	if !w.Keyword.Valid {
+		return true
+	}
+	keyword := strings.ToLower(w.Keyword.String)
+	return strings.Contains(strings.ToLower(p.Title), keyword) ||
+		strings.Contains(strings.ToLower(p.Description.String), keyword)
+}
+
+type jsonPayload struct {
+	Event     string    `json:"event"`
+	WebhookID uuid.UUID `json:"webhook_id"`
+	Post      jsonPost  `json:"post"`
+	Feed      jsonFeed  `json:"feed"`
+}
+
+type jsonPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Author      string     `json:"author"`
+	PublishedAt *time.Time `json:"published_at"`
+	FetchedAt   time.Time  `json:"fetched_at"`
+}
+
+type jsonFeed struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title     string        `json:"title"`
+	URL       string        `json:"url"`
+	Timestamp *time.Time    `json:"timestamp,omitempty"`
+	Footer    discordFooter `json:"footer"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// Payload returns the request body announcing p to w, in w's format.
+func Payload(w database.Webhook, p database.Post, f database.Feed) ([]byte, error) {
+	var published *time.Time
+	if p.PublishedAt.Valid {
+		published = &p.PublishedAt.Time
+	}
+
+	var v any
+	switch w.Format {
+	case FormatJSON:
+		v = jsonPayload{
+			Event:     "post.created",
+			WebhookID: w.ID,
+			Post: jsonPost{
+				ID:          p.ID,
+				Title:       p.Title,
+				URL:         p.Url,
+				Description: p.Description.String,
+				Author:      p.Author.String,
+				PublishedAt: published,
+				FetchedAt:   p.CreatedAt,
+			},
+			Feed: jsonFeed{ID: f.ID, Name: f.Name, URL: f.Url},
+		}
+	case FormatSlack:
+		v = slackPayload{
+			Text: fmt.Sprintf("<%s|%s> from %s", slackEscape(p.Url), slackEscape(p.Title), slackEscape(f.Name)),
+		}
+	case FormatDiscord:
+		v = discordPayload{Embeds: []discordEmbed{{
+			// Discord rejects embed titles over 256 characters.
+			Title:     truncate(p.Title, 256),
+			URL:       p.Url,
+			Timestamp: published,
+			Footer:    discordFooter{Text: truncate(f.Name, 2048)},
+		}}}
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", w.Format)
+	}
+	return json.Marshal(v)
+}
+
+// slackEscape escapes the characters Slack's message formatting treats as
+// control characters.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Sign returns the X-Gator-Signature header value for body sent at
+// timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+var client = &http.Client{Timeout: timeout}
+
+// Send POSTs a delivery's payload to url and returns the response status.
+// A non-2xx status is returned as an error too.
+func Send(ctx context.Context, url, secret string, deliveryID uuid.UUID, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("X-Gator-Event", "post.created")
+	req.Header.Set("X-Gator-Delivery", deliveryID.String())
+	req.Header.Set("X-Gator-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Gator-Signature", Sign(secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Retry decides what happens after a failed attempt. It returns when to try
+// again, or false if the delivery should be marked failed: after
+// MaxAttempts tries, or at once for client errors other than timeouts and
+// rate limiting, which retrying won't fix.
+func Retry(attempts int32, status int, now time.Time) (time.Time, bool) {
+	if attempts >= MaxAttempts {
+		return time.Time{}, false
+	}
+	if status >= 400 && status < 500 && status != http.StatusRequestTimeout && status != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+	return now.Add(firstRetry << (attempts - 1)), true
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// verify checks r the way the package comment tells receivers to, without
+// using Sign.
+func verify(r *http.Request, secret string, body []byte) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(r.Header.Get("X-Gator-Timestamp") + "."))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(r.Header.Get("X-Gator-Signature")), []byte(want))
+}
+
+func TestSend(t *testing.T) {
+	const secret = "whsec_test"
+	deliveryID := uuid.New()
+	body := []byte(`{"event":"post.created"}`)
+
+	var got *http.Request
+	var gotBody []byte
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	before := time.Now().Unix()
+	code, err := Send(context.Background(), srv.URL, secret, deliveryID, body)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("Send: got (%d, %v), want (204, nil)", code, err)
+	}
+	if string(gotBody) != string(body) {
+		t.Errorf("body = %s, want %s", gotBody, body)
+	}
+	if !verify(got, secret, gotBody) {
+		t.Errorf("signature %q does not verify", got.Header.Get("X-Gator-Signature"))
+	}
+	if verify(got, "whsec_other", gotBody) {
+		t.Error("signature verifies with the wrong secret")
+	}
+	if ts, err := strconv.ParseInt(got.Header.Get("X-Gator-Timestamp"), 10, 64); err != nil || ts < before || ts > time.Now().Unix() {
+		t.Errorf("X-Gator-Timestamp = %q, want the time of sending", got.Header.Get("X-Gator-Timestamp"))
+	}
+	for header, want := range map[string]string{
+		"Content-Type":     "application/json",
+		"X-Gator-Event":    "post.created",
+		"X-Gator-Delivery": deliveryID.String(),
+	} {
+		if v := got.Header.Get(header); v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+
+	status = http.StatusNotFound
+	code, err = Send(context.Background(), srv.URL, secret, deliveryID, body)
+	if err == nil || code != http.StatusNotFound {
+		t.Errorf("Send to a 404: got (%d, %v), want 404 and an error", code, err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		attempts int32
+		status   int
+		wait     time.Duration
+		retry    bool
+	}{
+		{"first server error", 1, http.StatusInternalServerError, time.Minute, true},
+		{"backoff doubles", 3, http.StatusBadGateway, 4 * time.Minute, true},
+		{"last retry", MaxAttempts - 1, http.StatusServiceUnavailable, 64 * time.Minute, true},
+		{"transport error", 2, 0, 2 * time.Minute, true},
+		{"timeout", 1, http.StatusRequestTimeout, time.Minute, true},
+		{"rate limited", 1, http.StatusTooManyRequests, time.Minute, true},
+		{"not found", 1, http.StatusNotFound, 0, false},
+		{"unauthorized", 1, http.StatusUnauthorized, 0, false},
+		{"out of attempts", MaxAttempts, http.StatusInternalServerError, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, ok := Retry(tt.attempts, tt.status, now)
+			if ok != tt.retry {
+				t.Fatalf("Retry(%d, %d) retries = %v, want %v", tt.attempts, tt.status, ok, tt.retry)
+			}
+			if ok && next.Sub(now) != tt.wait {
+				t.Errorf("Retry(%d, %d) waits %v, want %v", tt.attempts, tt.status, next.Sub(now), tt.wait)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	post := database.Post{
+		Title:       "Go 1.24 released",
+		Description: sql.NullString{String: "Generic type aliases and a faster map", Valid: true},
+	}
+	tests := []struct {
+		keyword string
+		want    bool
+	}{
+		{"", true},
+		{"go 1.24", true},
+		{"MAP", true},
+		{"rust", false},
+	}
+	for _, tt := range tests {
+		w := database.Webhook{Keyword: sql.NullString{String: tt.keyword, Valid: tt.keyword != ""}}
+		if got := Matches(w, post); got != tt.want {
+			t.Errorf("Matches(keyword %q) = %v, want %v", tt.keyword, got, tt.want)
+		}
+	}
+}
@@ -1,61 +1,228 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"fmt"
 	"gator/internal/cli"
 	"gator/internal/config"
-	"log"
+	"log/slog"
 	"os"
-
-	_ "github.com/lib/pq"
-
-	"gator/internal/database"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "Error: not enough arguments provided")
-		os.Exit(1)
-	}
-
 	cfg, err := config.Read()
 	if err != nil {
-		log.Fatalf("error reading config: %v", err)
+		slog.Error("failed to read config", "error", err)
+		os.Exit(1)
 	}
 
-	db, err := sql.Open("postgres", cfg.DBURL)
+	_, dbQueries, migrator, err := openStorage(cfg.DBURL)
 	if err != nil {
-		log.Fatalf("failed to open database: %v", err)
+		slog.Error("failed to open database", "error", err)
+		os.Exit(1)
 	}
 
-	dbQueries := database.New(db)
+	// ctx ends on SIGINT or SIGTERM, so every command can stop cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	state := &cli.State{
-		Config: &cfg,
-		DB:     dbQueries,
+		Ctx:      ctx,
+		Config:   &cfg,
+		DB:       dbQueries,
+		Migrator: migrator,
 	}
 
 	commands := cli.NewCommands()
-	commands.Register("register", cli.HandlerRegister)
-	commands.Register("login", cli.HandlerLogin)
-	commands.Register("reset", cli.HandlerReset)
-	commands.Register("users", cli.HandlerUsers)
-	commands.Register("agg", cli.HandlerAgg)
-	commands.Register("addfeed", cli.MiddlewareLoggedIn(cli.HandlerAddFeedLogged))
-	commands.Register("feeds", cli.HandlerFeeds)
-	commands.Register("follow", cli.MiddlewareLoggedIn(cli.HandlerFollowLogged))
-	commands.Register("following", cli.MiddlewareLoggedIn(cli.HandlerFollowingLogged))
-	commands.Register("unfollow", cli.MiddlewareLoggedIn(cli.HandlerUnfollowLogged))
-	commands.Register("browse", cli.MiddlewareLoggedIn(cli.HandlerBrowsePostsLogged))
+	commands.Register("migrate", cli.CommandInfo{
+		Usage:       "up|down|status|to <version>",
+		Description: "Apply, roll back or inspect database schema migrations",
+		MinArgs:     1,
+	}, cli.HandlerMigrate)
+	commands.Register("register", cli.CommandInfo{
+		Usage:       "[flags] <username>",
+		Description: "Create a user with a password and log in as them",
+		MinArgs:     1,
+		Flags:       cli.RegisterFlags,
+	}, cli.HandlerRegister)
+	commands.Register("login", cli.CommandInfo{
+		Usage:       "[flags] <username|email>",
+		Description: "Log in as an existing user",
+		MinArgs:     1,
+		Flags:       cli.LoginFlags,
+	}, cli.HandlerLogin)
+	commands.Register("passwd", cli.CommandInfo{
+		Description: "Change your password",
+	}, cli.MiddlewareLoggedIn(cli.HandlerPasswdLogged))
+	commands.Register("apikey", cli.CommandInfo{
+		Usage:       "create [flags] | list | revoke <id|prefix>",
+		Description: "Manage your API keys",
+		MinArgs:     1,
+		Flags:       cli.APIKeyFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerAPIKeyLogged))
+	commands.Register("webhook", cli.CommandInfo{
+		Usage:       "add [flags] <url> | list | remove <id> | log [flags]",
+		Description: "Send new posts to webhook endpoints and view deliveries",
+		MinArgs:     1,
+		Flags:       cli.WebhookFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerWebhookLogged))
+	commands.Register("digest", cli.CommandInfo{
+		Usage:       "daily|weekly [flags] | off | status | preview [flags] | send [flags] | run [flags]",
+		Description: "Email a digest of unread posts, and send or schedule due digests",
+		MinArgs:     1,
+		Flags:       cli.DigestFlags,
+	}, cli.HandlerDigest)
+	commands.Register("reset", cli.CommandInfo{
+		Usage:       "[flags] [all|feeds|follows|posts]",
+		Description: "Delete all data, or only feeds, follows or posts, after saving a JSON snapshot",
+		Flags:       cli.ResetFlags,
+	}, cli.HandlerReset)
+	commands.Register("users", cli.CommandInfo{
+		Description: "List users with their follow and feed counts",
+	}, cli.HandlerUsers)
+	commands.Register("deluser", cli.CommandInfo{
+		Usage:       "[flags] <username>",
+		Description: "Delete a user and everything they own",
+		MinArgs:     1,
+		Flags:       cli.DelUserFlags,
+	}, cli.HandlerDelUser)
+	commands.Register("renameuser", cli.CommandInfo{
+		Usage:       "<old-name> <new-name>",
+		Description: "Rename a user",
+		MinArgs:     2,
+	}, cli.HandlerRenameUser)
+	commands.Register("serve", cli.CommandInfo{
+		Usage:       "[flags]",
+		Description: "Serve a JSON REST API until interrupted",
+		Flags:       cli.ServeFlags,
+	}, cli.HandlerServe)
+	commands.Register("agg", cli.CommandInfo{
+		Usage:       "[flags] <time_between_reqs>",
+		Description: "Fetch feeds continuously, one every interval (e.g. 1m)",
+		MinArgs:     1,
+		Flags:       cli.AggFlags,
+	}, cli.HandlerAgg)
+	commands.Register("prune", cli.CommandInfo{
+		Usage:       "[flags]",
+		Description: "Delete posts outside the configured retention policy",
+		Flags:       cli.PruneFlags,
+	}, cli.HandlerPrune)
+	commands.Register("addfeed", cli.CommandInfo{
+		Usage:       "<name> <url>",
+		Description: "Add a feed and follow it",
+		MinArgs:     2,
+	}, cli.MiddlewareLoggedIn(cli.HandlerAddFeedLogged))
+	commands.Register("feeds", cli.CommandInfo{
+		Description: "List all feeds",
+	}, cli.HandlerFeeds)
+	commands.Register("rmfeed", cli.CommandInfo{
+		Usage:       "[flags] <url>",
+		Description: "Delete a feed you added",
+		MinArgs:     1,
+		Flags:       cli.RmFeedFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerRmFeedLogged))
+	commands.Register("renamefeed", cli.CommandInfo{
+		Usage:       "<url> <name>",
+		Description: "Rename a feed you added",
+		MinArgs:     2,
+	}, cli.MiddlewareLoggedIn(cli.HandlerRenameFeedLogged))
+	commands.Register("setfeedurl", cli.CommandInfo{
+		Usage:       "[flags] <old-url> <new-url>",
+		Description: "Change the URL of a feed you added",
+		MinArgs:     2,
+		Flags:       cli.SetFeedURLFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerSetFeedURLLogged))
+	commands.Register("follow", cli.CommandInfo{
+		Usage:       "<url>",
+		Description: "Follow an existing feed",
+		MinArgs:     1,
+	}, cli.MiddlewareLoggedIn(cli.HandlerFollowLogged))
+	commands.Register("following", cli.CommandInfo{
+		Description: "List the feeds you follow, grouped by tag",
+	}, cli.MiddlewareLoggedIn(cli.HandlerFollowingLogged))
+	commands.Register("unfollow", cli.CommandInfo{
+		Usage:       "<url>",
+		Description: "Stop following a feed",
+		MinArgs:     1,
+	}, cli.MiddlewareLoggedIn(cli.HandlerUnfollowLogged))
+	commands.Register("browse", cli.CommandInfo{
+		Usage:       "[flags] [limit]",
+		Description: "Show posts from the feeds you follow",
+		Flags:       cli.BrowseFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerBrowsePostsLogged))
+	commands.Register("read", cli.CommandInfo{
+		Usage:       "<post-id|url>",
+		Description: "Mark a post as read",
+		MinArgs:     1,
+	}, cli.MiddlewareLoggedIn(cli.HandlerReadLogged))
+	commands.Register("unread", cli.CommandInfo{
+		Usage:       "<post-id|url>",
+		Description: "Mark a post as unread",
+		MinArgs:     1,
+	}, cli.MiddlewareLoggedIn(cli.HandlerUnreadLogged))
+	commands.Register("markall", cli.CommandInfo{
+		Usage:       "read [flags]",
+		Description: "Mark many posts as read at once",
+		MinArgs:     1,
+		Flags:       cli.MarkAllFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerMarkAllLogged))
+	commands.Register("star", cli.CommandInfo{
+		Usage:       "[flags] <post-id|url>",
+		Description: "Save a post, keeping a copy even if it is pruned",
+		MinArgs:     1,
+		Flags:       cli.StarFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerStarLogged))
+	commands.Register("unstar", cli.CommandInfo{
+		Usage:       "<post-id|url>",
+		Description: "Remove a saved post",
+		MinArgs:     1,
+	}, cli.MiddlewareLoggedIn(cli.HandlerUnstarLogged))
+	commands.Register("saved", cli.CommandInfo{
+		Usage:       "[flags]",
+		Description: "List your saved posts",
+		Flags:       cli.SavedFlags,
+	}, cli.MiddlewareLoggedIn(cli.HandlerSavedLogged))
+	commands.Register("tui", cli.CommandInfo{
+		Description: "Read posts in a full-screen terminal interface",
+	}, cli.MiddlewareLoggedIn(cli.HandlerTUILogged))
+	commands.Register("tag", cli.CommandInfo{
+		Usage:       "<url> <tag>",
+		Description: "Tag a feed you follow",
+		MinArgs:     2,
+	}, cli.MiddlewareLoggedIn(cli.HandlerTagLogged))
+	commands.Register("untag", cli.CommandInfo{
+		Usage:       "<url> <tag>",
+		Description: "Remove a tag from a feed you follow",
+		MinArgs:     2,
+	}, cli.MiddlewareLoggedIn(cli.HandlerUntagLogged))
+
+	if len(os.Args) < 2 {
+		commands.PrintHelp(os.Stderr, "")
+		os.Exit(1)
+	}
 
 	cmd := cli.Command{
 		Name: os.Args[1],
 		Args: os.Args[2:],
 	}
 
+	if commands.Has(cmd.Name) && cmd.Name != "migrate" && cmd.Name != "help" && !commands.WantsHelp(cmd) {
+		if err := migrator.Check(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+	}
+
 	if err := commands.Run(state, cmd); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
+		var usageErr *cli.UsageError
+		if errors.As(err, &usageErr) {
+			fmt.Fprintln(os.Stderr)
+			commands.PrintHelp(os.Stderr, usageErr.Command)
+		}
 		os.Exit(1)
 	}
 }
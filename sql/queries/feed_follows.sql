@@ -21,7 +21,15 @@ ff.updated_at,
 ff.user_id,
 ff.feed_id,
 f.name AS feed_name,
-u.name AS user_name
+f.url AS feed_url,
+u.name AS user_name,
+(
+    SELECT COUNT(*)
+    FROM posts p
+    LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+    WHERE p.feed_id = ff.feed_id
+    AND ups.read_at IS NULL
+) AS unread_count
 FROM feed_follows ff
 INNER JOIN feeds f ON f.id = ff.feed_id
 INNER JOIN users u ON u.id = ff.user_id
@@ -32,4 +40,18 @@ DELETE FROM feed_follows
 USING feeds
 WHERE feed_follows.feed_id = feeds.id
 AND feed_follows.user_id = $1
-AND feeds.url = $2;
\ No newline at end of file
+AND feeds.url = $2;
+
+-- name: GetFeedFollowForUserByURL :one
+SELECT ff.*
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+WHERE ff.user_id = $1
+AND f.url = $2;
+
+-- name: ListFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at;
+
+-- name: ResetFeedFollows :exec
+DELETE FROM feed_follows;
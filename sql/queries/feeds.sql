@@ -25,4 +25,50 @@ WHERE id = $1;
 SELECT *
 FROM feeds
 ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: CountDueFeeds :one
+SELECT COUNT(*)
+FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at < sqlc.arg('before');
+
+-- name: ListFeeds :many
+SELECT * FROM feeds
+ORDER BY name;
+
+-- name: GetFeedFollowers :many
+SELECT users.*
+FROM feed_follows
+JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY feed_follows.created_at;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = $2,
+    last_fetched_at = NULL,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+-- name: DeletePostsForFeed :execrows
+DELETE FROM posts WHERE feed_id = $1;
+
+-- name: ResetFeeds :exec
+DELETE FROM feeds;
@@ -1,11 +1,99 @@
--- name: CreatePost :exec
-INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+-- name: CreatePost :execrows
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (url) DO NOTHING;
 
 -- name: GetPostsForUSer :many
 SELECT p.*
 FROM posts p
 JOIN feed_follows ff ON ff.feed_id = p.feed_id
-WHERE ff.user_id = $1
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (
+    sqlc.narg('tag')::text IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = sqlc.narg('tag')
+    )
+)
 ORDER BY p.published_at DESC
-LIMIT $2;
\ No newline at end of file
+LIMIT sqlc.arg('limit');
+
+-- name: GetPostByID :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: GetPostByURL :one
+SELECT * FROM posts WHERE url = $1;
+
+
+-- name: BrowsePostsForUser :many
+SELECT
+p.*,
+f.name AS feed_name,
+ups.read_at
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (NOT sqlc.arg('unread_only')::boolean OR ups.read_at IS NULL)
+AND (
+    sqlc.narg('tag')::text IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = sqlc.narg('tag')
+    )
+)
+AND (sqlc.narg('feed_url')::text IS NULL OR f.url = sqlc.narg('feed_url'))
+AND (sqlc.narg('author')::text IS NULL OR p.author ILIKE '%' || sqlc.narg('author') || '%')
+AND (sqlc.narg('since')::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) >= sqlc.narg('since'))
+AND (sqlc.narg('until')::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg('until'))
+AND (
+    sqlc.narg('after_id')::uuid IS NULL
+    OR (
+        sqlc.arg('sort')::text = 'feed'
+        AND (
+            f.name > sqlc.narg('after_feed')::text
+            OR (
+                f.name = sqlc.narg('after_feed')::text
+                AND (COALESCE(p.published_at, p.created_at), p.id) < (sqlc.narg('after_time')::timestamp, sqlc.narg('after_id')::uuid)
+            )
+        )
+    )
+    OR (
+        sqlc.arg('sort')::text = 'fetched'
+        AND (p.created_at, p.id) < (sqlc.narg('after_time')::timestamp, sqlc.narg('after_id')::uuid)
+    )
+    OR (
+        sqlc.arg('sort')::text = 'published'
+        AND (COALESCE(p.published_at, p.created_at), p.id) < (sqlc.narg('after_time')::timestamp, sqlc.narg('after_id')::uuid)
+    )
+)
+ORDER BY
+    CASE WHEN sqlc.arg('sort')::text = 'feed' THEN f.name END ASC,
+    CASE WHEN sqlc.arg('sort')::text = 'fetched' THEN p.created_at ELSE COALESCE(p.published_at, p.created_at) END DESC,
+    p.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserAfter :many
+SELECT p.*, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (p.created_at, p.id) > (sqlc.arg('after_time')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListPosts :many
+SELECT * FROM posts
+ORDER BY created_at;
+
+-- name: ResetPosts :exec
+DELETE FROM posts;
@@ -0,0 +1,36 @@
+-- name: StarPost :one
+INSERT INTO starred_posts (id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note)
+SELECT sqlc.arg('id'), now(), now(), sqlc.arg('user_id'), p.id, f.name, p.title, p.url, p.description, p.published_at, sqlc.arg('note')
+FROM posts p
+JOIN feeds f ON f.id = p.feed_id
+WHERE p.id = sqlc.arg('post_id')
+ON CONFLICT (user_id, url) DO UPDATE
+SET note = EXCLUDED.note,
+    updated_at = now()
+RETURNING *;
+
+-- name: UnstarPost :execrows
+DELETE FROM starred_posts
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    id::text = sqlc.arg('ref')::text
+    OR post_id::text = sqlc.arg('ref')::text
+    OR url = sqlc.arg('ref')::text
+);
+
+-- name: GetStarredPostsForUser :many
+SELECT *
+FROM starred_posts
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: CountStarredPostsForUser :one
+SELECT COUNT(*)
+FROM starred_posts
+WHERE user_id = $1;
+
+-- name: ListStarredPosts :many
+SELECT * FROM starred_posts
+ORDER BY created_at;
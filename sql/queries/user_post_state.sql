@@ -0,0 +1,30 @@
+-- name: MarkPostRead :exec
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+VALUES ($1, $2, now(), now(), now())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = now(),
+    updated_at = now();
+
+-- name: MarkPostUnread :exec
+UPDATE user_post_state
+SET read_at = NULL,
+    updated_at = now()
+WHERE user_id = $1
+AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+SELECT ff.user_id, p.id, now(), now(), now()
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (sqlc.narg('feed_url')::text IS NULL OR f.url = sqlc.narg('feed_url'))
+AND (sqlc.narg('before')::timestamp IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg('before'))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = now(),
+    updated_at = now()
+WHERE user_post_state.read_at IS NULL;
+
+-- name: ListUserPostStates :many
+SELECT * FROM user_post_state;
@@ -1,18 +1,78 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, email, password_hash)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 RETURNING *;
 
 -- name: GetUser :one
 SELECT * FROM users WHERE name = $1;
 
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = $1;
+
 -- name: ResetUsers :exec
 DELETE FROM users;
 
 -- name: GetUsers :many
-SELECT * FROM users;
\ No newline at end of file
+SELECT * FROM users;
+
+-- name: GetUserStats :many
+-- GREATEST ignores NULLs, so users without any activity fall back to
+-- updated_at.
+SELECT
+    users.*,
+    (SELECT count(*) FROM feed_follows WHERE feed_follows.user_id = users.id) AS follow_count,
+    (SELECT count(*) FROM feeds WHERE feeds.user_id = users.id) AS feed_count,
+    GREATEST(
+        users.updated_at,
+        (SELECT max(created_at) FROM feeds WHERE feeds.user_id = users.id),
+        (SELECT max(created_at) FROM feed_follows WHERE feed_follows.user_id = users.id),
+        (SELECT max(read_at) FROM user_post_state WHERE user_post_state.user_id = users.id),
+        (SELECT max(created_at) FROM starred_posts WHERE starred_posts.user_id = users.id)
+    )::TIMESTAMP AS last_active_at
+FROM users
+ORDER BY users.name;
+
+-- name: RenameUser :one
+UPDATE users
+SET name = $2,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    failed_logins = 0,
+    locked_until = NULL,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: SetUserEmail :exec
+UPDATE users
+SET email = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: SetLoginFailures :exec
+UPDATE users
+SET failed_logins = $2,
+    locked_until = $3
+WHERE id = $1;
+
+-- name: RecordFailedLogin :one
+-- Counts a wrong password in a single statement so that concurrent attempts
+-- can't overwrite each other's count.
+UPDATE users
+SET failed_logins = failed_logins + 1
+WHERE id = $1
+RETURNING failed_logins;
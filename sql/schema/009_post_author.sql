@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT NULL;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN author;
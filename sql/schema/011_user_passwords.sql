@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email TEXT NULL,
+ADD COLUMN password_hash TEXT NULL,
+ADD COLUMN failed_logins INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN locked_until TIMESTAMP NULL;
+
+CREATE UNIQUE INDEX users_email_key ON users (email);
+
+-- +goose Down
+DROP INDEX users_email_key;
+
+ALTER TABLE users
+DROP COLUMN locked_until,
+DROP COLUMN failed_logins,
+DROP COLUMN password_hash,
+DROP COLUMN email;
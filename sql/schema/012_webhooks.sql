@@ -0,0 +1,47 @@
+-- +goose Up
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    format TEXT NOT NULL,
+    feed_id UUID,
+    tag TEXT,
+    keyword TEXT,
+    CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_webhooks_feed FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    webhook_id UUID NOT NULL,
+    post_id UUID,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    response_status INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id)
+    REFERENCES webhooks(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_webhook_deliveries_post FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE SET NULL,
+    CONSTRAINT unique_webhook_delivery UNIQUE (webhook_id, post_id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (status, next_attempt_at);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
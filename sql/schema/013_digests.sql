@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE digest_subscriptions (
+    user_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    frequency TEXT NOT NULL,
+    last_sent_at TIMESTAMP,
+    CONSTRAINT fk_digest_subscriptions_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE digest_subscriptions;
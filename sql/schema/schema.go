@@ -0,0 +1,7 @@
+// Package schema embeds the goose migrations that define the database schema.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
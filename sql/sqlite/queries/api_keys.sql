@@ -0,0 +1,31 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (id, created_at, updated_at, user_id, name, prefix, key_hash, scope)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+RETURNING *;
+
+-- name: ListAPIKeysForUser :many
+SELECT * FROM api_keys
+WHERE user_id = ?1
+ORDER BY created_at;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at;
+
+-- name: GetUserByAPIKey :one
+SELECT sqlc.embed(users), api_keys.scope FROM users
+INNER JOIN api_keys ON api_keys.user_id = users.id
+WHERE api_keys.key_hash = ?1;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = ?2
+WHERE key_hash = ?1;
+
+-- name: RevokeAPIKey :execrows
+DELETE FROM api_keys
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    id = CAST(sqlc.arg('ref') AS TEXT)
+    OR prefix = CAST(sqlc.arg('ref') AS TEXT)
+);
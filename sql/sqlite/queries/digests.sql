@@ -0,0 +1,43 @@
+-- name: SetDigestFrequency :one
+INSERT INTO digest_subscriptions (user_id, created_at, updated_at, frequency)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user_id) DO UPDATE
+SET frequency = EXCLUDED.frequency,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE user_id = ?1;
+
+-- name: GetDigestSubscription :one
+SELECT * FROM digest_subscriptions
+WHERE user_id = ?1;
+
+-- name: GetDigestSubscriptions :many
+SELECT ds.*, u.name AS user_name, u.email
+FROM digest_subscriptions ds
+JOIN users u ON u.id = ds.user_id
+ORDER BY u.name;
+
+-- name: ListDigestSubscriptions :many
+SELECT * FROM digest_subscriptions
+ORDER BY created_at;
+
+-- name: SetDigestSent :exec
+UPDATE digest_subscriptions
+SET last_sent_at = ?2
+WHERE user_id = ?1;
+
+-- name: GetDigestPosts :many
+SELECT p.*, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND ups.read_at IS NULL
+AND p.created_at > sqlc.arg('since')
+AND p.created_at <= sqlc.arg('until')
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,54 @@
+-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING
+id,
+created_at,
+updated_at,
+user_id,
+feed_id,
+(SELECT name FROM feeds WHERE feeds.id = feed_id) AS feed_name,
+(SELECT name FROM users WHERE users.id = user_id) AS user_name;
+
+-- name: GetFeedFollowsForUser :many
+SELECT
+ff.id,
+ff.created_at,
+ff.updated_at,
+ff.user_id,
+ff.feed_id,
+f.name AS feed_name,
+f.url AS feed_url,
+u.name AS user_name,
+(
+    SELECT COUNT(*)
+    FROM posts p
+    LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+    WHERE p.feed_id = ff.feed_id
+    AND ups.read_at IS NULL
+) AS unread_count
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+INNER JOIN users u ON u.id = ff.user_id
+WHERE ff.user_id = ?1;
+
+-- name: DeleteFeedFollow :exec
+DELETE FROM feed_follows
+WHERE feed_follows.user_id = ?1
+AND feed_follows.feed_id IN (
+    SELECT feeds.id FROM feeds WHERE feeds.url = ?2
+);
+
+-- name: GetFeedFollowForUserByURL :one
+SELECT ff.*
+FROM feed_follows ff
+INNER JOIN feeds f ON f.id = ff.feed_id
+WHERE ff.user_id = ?1
+AND f.url = ?2;
+
+-- name: ListFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at;
+
+-- name: ResetFeedFollows :exec
+DELETE FROM feed_follows;
@@ -0,0 +1,74 @@
+-- name: CreateFeed :one
+
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING *;
+
+-- name: GetAllFeeds :many
+SELECT
+f.name AS feed_name,
+f.url,
+u.name AS user_name
+FROM feeds f
+JOIN users u ON f.user_id = u.id;
+
+-- name: GetFeedByURL :one
+SELECT * FROM feeds WHERE url = ?1;
+
+-- name: MarkedFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1;
+
+-- name: GetNextFeedToFetch :one
+SELECT *
+FROM feeds
+ORDER BY last_fetched_at ASC NULLS FIRST
+LIMIT 1;
+
+-- name: CountDueFeeds :one
+SELECT COUNT(*)
+FROM feeds
+WHERE last_fetched_at IS NULL OR last_fetched_at < sqlc.arg('before');
+
+-- name: ListFeeds :many
+SELECT * FROM feeds
+ORDER BY name;
+
+-- name: GetFeedFollowers :many
+SELECT users.*
+FROM feed_follows
+JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = ?1
+ORDER BY feed_follows.created_at;
+
+-- name: RenameFeed :one
+UPDATE feeds
+SET name = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+RETURNING *;
+
+-- name: UpdateFeedURL :one
+UPDATE feeds
+SET url = ?2,
+    last_fetched_at = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1
+RETURNING *;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = ?2,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?1;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = ?1;
+
+-- name: DeletePostsForFeed :execrows
+DELETE FROM posts WHERE feed_id = ?1;
+
+-- name: ResetFeeds :exec
+DELETE FROM feeds;
@@ -0,0 +1,122 @@
+-- name: CreatePost :execrows
+INSERT INTO posts(id, created_at, updated_at, title, url, description, published_at, feed_id, author)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+ON CONFLICT (url) DO NOTHING;
+
+-- name: GetPostsForUSer :many
+SELECT p.*
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (
+    CAST(sqlc.narg('tag') AS TEXT) IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = sqlc.narg('tag')
+    )
+)
+-- Match PostgreSQL, which puts posts without a publication date first.
+ORDER BY p.published_at DESC NULLS FIRST
+LIMIT sqlc.arg('limit');
+
+-- name: GetPostByID :one
+SELECT * FROM posts WHERE id = ?1;
+
+-- name: GetPostByURL :one
+SELECT * FROM posts WHERE url = ?1;
+
+-- name: BrowsePostsForUser :many
+SELECT
+p.*,
+f.name AS feed_name,
+ups.read_at
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+LEFT JOIN user_post_state ups ON ups.post_id = p.id AND ups.user_id = ff.user_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (CAST(sqlc.arg('unread_only') AS BOOLEAN) = FALSE OR ups.read_at IS NULL)
+AND (
+    CAST(sqlc.narg('tag') AS TEXT) IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = sqlc.narg('tag')
+    )
+)
+AND (CAST(sqlc.narg('feed_url') AS TEXT) IS NULL OR f.url = sqlc.narg('feed_url'))
+AND (CAST(sqlc.narg('author') AS TEXT) IS NULL OR p.author LIKE '%' || sqlc.narg('author') || '%')
+AND (CAST(sqlc.narg('since') AS TIMESTAMP) IS NULL OR COALESCE(p.published_at, p.created_at) >= sqlc.narg('since'))
+AND (CAST(sqlc.narg('until') AS TIMESTAMP) IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg('until'))
+AND (
+    sqlc.narg('after_id') IS NULL
+    OR (
+        CAST(sqlc.arg('sort') AS TEXT) = 'feed'
+        AND (
+            f.name > CAST(sqlc.narg('after_feed') AS TEXT)
+            OR (
+                f.name = CAST(sqlc.narg('after_feed') AS TEXT)
+                AND (
+                    COALESCE(p.published_at, p.created_at) < sqlc.narg('after_time')
+                    OR (
+                        COALESCE(p.published_at, p.created_at) = sqlc.narg('after_time')
+                        AND p.id < sqlc.narg('after_id')
+                    )
+                )
+            )
+        )
+    )
+    OR (
+        CAST(sqlc.arg('sort') AS TEXT) = 'fetched'
+        AND (
+            p.created_at < sqlc.narg('after_time')
+            OR (
+                p.created_at = sqlc.narg('after_time')
+                AND p.id < sqlc.narg('after_id')
+            )
+        )
+    )
+    OR (
+        CAST(sqlc.arg('sort') AS TEXT) = 'published'
+        AND (
+            COALESCE(p.published_at, p.created_at) < sqlc.narg('after_time')
+            OR (
+                COALESCE(p.published_at, p.created_at) = sqlc.narg('after_time')
+                AND p.id < sqlc.narg('after_id')
+            )
+        )
+    )
+)
+-- sqlc does not rewrite named parameters inside ORDER BY for SQLite, so the
+-- sort parameter is referenced by the position sqlc assigns it above.
+ORDER BY
+    CASE WHEN ?9 = 'feed' THEN f.name END ASC,
+    CASE WHEN ?9 = 'fetched' THEN p.created_at ELSE COALESCE(p.published_at, p.created_at) END DESC,
+    p.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserAfter :many
+SELECT p.*, f.name AS feed_name, f.url AS feed_url
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (
+    p.created_at > sqlc.arg('after_time')
+    OR (p.created_at = sqlc.arg('after_time') AND p.id > sqlc.arg('after_id'))
+)
+ORDER BY p.created_at, p.id
+LIMIT sqlc.arg('limit');
+
+-- name: ListPosts :many
+SELECT * FROM posts
+ORDER BY created_at;
+
+-- name: ResetPosts :exec
+DELETE FROM posts;
@@ -0,0 +1,38 @@
+-- name: GetPrunablePosts :many
+SELECT
+ranked.id,
+ranked.title,
+ranked.url
+FROM (
+    SELECT
+    p.id,
+    p.title,
+    p.url,
+    p.feed_id,
+    COALESCE(p.published_at, p.created_at) AS sort_time,
+    ROW_NUMBER() OVER (ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC) AS position
+    FROM posts p
+    WHERE p.feed_id = sqlc.arg('feed_id')
+) ranked
+WHERE (
+    (CAST(sqlc.narg('older_than') AS TIMESTAMP) IS NOT NULL AND ranked.sort_time < sqlc.narg('older_than'))
+    OR (CAST(sqlc.narg('max_posts') AS BIGINT) IS NOT NULL AND ranked.position > sqlc.narg('max_posts'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM starred_posts sp WHERE sp.post_id = ranked.id
+)
+AND (
+    CAST(sqlc.arg('keep_unread') AS BOOLEAN) = FALSE
+    OR NOT EXISTS (
+        SELECT 1
+        FROM feed_follows ff
+        LEFT JOIN user_post_state ups ON ups.post_id = ranked.id AND ups.user_id = ff.user_id
+        WHERE ff.feed_id = ranked.feed_id
+        AND ups.read_at IS NULL
+    )
+)
+ORDER BY ranked.position;
+
+-- name: DeletePosts :execrows
+DELETE FROM posts
+WHERE id IN (sqlc.slice('ids'));
@@ -0,0 +1,36 @@
+-- name: StarPost :one
+INSERT INTO starred_posts (id, created_at, updated_at, user_id, post_id, feed_name, title, url, description, published_at, note)
+SELECT sqlc.arg('id'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), sqlc.arg('user_id'), p.id, f.name, p.title, p.url, p.description, p.published_at, sqlc.arg('note')
+FROM posts p
+JOIN feeds f ON f.id = p.feed_id
+WHERE p.id = sqlc.arg('post_id')
+ON CONFLICT (user_id, url) DO UPDATE
+SET note = excluded.note,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+RETURNING *;
+
+-- name: UnstarPost :execrows
+DELETE FROM starred_posts
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    id = CAST(sqlc.arg('ref') AS TEXT)
+    OR post_id = CAST(sqlc.arg('ref') AS TEXT)
+    OR url = CAST(sqlc.arg('ref') AS TEXT)
+);
+
+-- name: GetStarredPostsForUser :many
+SELECT *
+FROM starred_posts
+WHERE user_id = ?1
+ORDER BY created_at DESC, id DESC
+LIMIT ?2
+OFFSET ?3;
+
+-- name: CountStarredPostsForUser :one
+SELECT COUNT(*)
+FROM starred_posts
+WHERE user_id = ?1;
+
+-- name: ListStarredPosts :many
+SELECT * FROM starred_posts
+ORDER BY created_at;
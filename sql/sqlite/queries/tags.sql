@@ -0,0 +1,44 @@
+-- name: CreateTag :one
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (user_id, name) DO UPDATE
+SET updated_at = tags.updated_at
+RETURNING *;
+
+-- name: AddFeedFollowTag :exec
+INSERT INTO feed_follow_tags (feed_follow_id, tag_id, created_at)
+VALUES (?1, ?2, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+ON CONFLICT (feed_follow_id, tag_id) DO NOTHING;
+
+-- name: RemoveFeedFollowTag :execrows
+DELETE FROM feed_follow_tags
+WHERE feed_follow_tags.feed_follow_id = ?1
+AND feed_follow_tags.tag_id IN (
+    SELECT tags.id FROM tags WHERE tags.name = ?2
+);
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE user_id = ?1
+AND NOT EXISTS (
+    SELECT 1 FROM feed_follow_tags fft WHERE fft.tag_id = tags.id
+);
+
+-- name: GetFeedFollowTagsForUser :many
+SELECT
+fft.feed_follow_id,
+t.name AS tag_name
+FROM feed_follow_tags fft
+INNER JOIN tags t ON t.id = fft.tag_id
+WHERE t.user_id = ?1
+ORDER BY t.name;
+
+-- name: ListTags :many
+SELECT * FROM tags
+ORDER BY created_at;
+
+-- name: ListFeedFollowTags :many
+SELECT * FROM feed_follow_tags;
+
+-- name: ResetTags :exec
+DELETE FROM tags;
@@ -0,0 +1,30 @@
+-- name: MarkPostRead :exec
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+VALUES (?1, ?2, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+
+-- name: MarkPostUnread :exec
+UPDATE user_post_state
+SET read_at = NULL,
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE user_id = ?1
+AND post_id = ?2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO user_post_state (user_id, post_id, created_at, updated_at, read_at)
+SELECT ff.user_id, p.id, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+FROM posts p
+JOIN feed_follows ff ON ff.feed_id = p.feed_id
+JOIN feeds f ON f.id = p.feed_id
+WHERE ff.user_id = sqlc.arg('user_id')
+AND (CAST(sqlc.narg('feed_url') AS TEXT) IS NULL OR f.url = sqlc.narg('feed_url'))
+AND (CAST(sqlc.narg('before') AS TIMESTAMP) IS NULL OR COALESCE(p.published_at, p.created_at) < sqlc.narg('before'))
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE user_post_state.read_at IS NULL;
+
+-- name: ListUserPostStates :many
+SELECT * FROM user_post_state;
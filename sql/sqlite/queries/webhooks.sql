@@ -0,0 +1,82 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, secret, format, feed_id, tag, keyword)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT w.*, f.url AS feed_url
+FROM webhooks w
+LEFT JOIN feeds f ON f.id = w.feed_id
+WHERE w.user_id = ?1
+ORDER BY w.created_at;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+ORDER BY created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE user_id = sqlc.arg('user_id')
+AND id = CAST(sqlc.arg('ref') AS TEXT);
+
+-- name: GetWebhooksForFeed :many
+SELECT w.*
+FROM webhooks w
+JOIN feed_follows ff ON ff.user_id = w.user_id AND ff.feed_id = sqlc.arg('feed_id')
+WHERE (w.feed_id IS NULL OR w.feed_id = sqlc.arg('feed_id'))
+AND (
+    w.tag IS NULL
+    OR EXISTS (
+        SELECT 1
+        FROM feed_follow_tags fft
+        JOIN tags t ON t.id = fft.tag_id
+        WHERE fft.feed_follow_id = ff.id
+        AND t.name = w.tag
+    )
+)
+ORDER BY w.created_at;
+
+-- name: CreateWebhookDelivery :execrows
+INSERT INTO webhook_deliveries (id, created_at, updated_at, webhook_id, post_id, payload, status, next_attempt_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, 'pending', ?7)
+ON CONFLICT (webhook_id, post_id) DO NOTHING;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT d.*, w.url, w.secret
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+WHERE d.status = 'pending'
+AND d.next_attempt_at <= sqlc.arg('now')
+ORDER BY d.next_attempt_at, d.id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET updated_at = ?2,
+    status = ?3,
+    attempts = ?4,
+    next_attempt_at = ?5,
+    response_status = ?6,
+    last_error = ?7,
+    delivered_at = ?8
+WHERE id = ?1;
+
+-- name: GetWebhookDeliveriesForUser :many
+SELECT
+d.id,
+d.created_at,
+d.webhook_id,
+w.url AS webhook_url,
+p.title AS post_title,
+d.status,
+d.attempts,
+d.next_attempt_at,
+d.response_status,
+d.last_error,
+d.delivered_at
+FROM webhook_deliveries d
+JOIN webhooks w ON w.id = d.webhook_id
+LEFT JOIN posts p ON p.id = d.post_id
+WHERE w.user_id = sqlc.arg('user_id')
+ORDER BY d.created_at DESC, d.id DESC
+LIMIT sqlc.arg('limit');
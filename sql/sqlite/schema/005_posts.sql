@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE posts(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_id UUID NOT NULL,
+    CONSTRAINT fk_post_feed FOREIGN KEY (feed_id)
+    REFERENCES feeds(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE posts;
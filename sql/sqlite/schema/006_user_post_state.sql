@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE user_post_state (
+    user_id UUID NOT NULL,
+    post_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP,
+    PRIMARY KEY (user_id, post_id),
+    CONSTRAINT fk_user_post_state_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_user_post_state_post FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE user_post_state;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE starred_posts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    post_id UUID,
+    feed_name TEXT NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    published_at TIMESTAMP,
+    note TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_starred_posts_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_starred_posts_post FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE SET NULL,
+    CONSTRAINT unique_starred_post UNIQUE (user_id, url)
+);
+
+-- +goose Down
+DROP TABLE starred_posts;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE tags (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    CONSTRAINT fk_tags_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+    CONSTRAINT unique_tag UNIQUE (user_id, name)
+);
+
+CREATE TABLE feed_follow_tags (
+    feed_follow_id UUID NOT NULL,
+    tag_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_follow_id, tag_id),
+    CONSTRAINT fk_feed_follow_tags_follow FOREIGN KEY (feed_follow_id)
+    REFERENCES feed_follows(id)
+    ON DELETE CASCADE,
+    CONSTRAINT fk_feed_follow_tags_tag FOREIGN KEY (tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE feed_follow_tags;
+DROP TABLE tags;
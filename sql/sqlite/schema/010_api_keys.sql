@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    last_used_at TIMESTAMP,
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE api_keys;
@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN email TEXT;
+
+ALTER TABLE users
+ADD COLUMN password_hash TEXT;
+
+ALTER TABLE users
+ADD COLUMN failed_logins INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE users
+ADD COLUMN locked_until TIMESTAMP;
+
+CREATE UNIQUE INDEX users_email_key ON users (email);
+
+-- +goose Down
+DROP INDEX users_email_key;
+
+ALTER TABLE users
+DROP COLUMN locked_until;
+
+ALTER TABLE users
+DROP COLUMN failed_logins;
+
+ALTER TABLE users
+DROP COLUMN password_hash;
+
+ALTER TABLE users
+DROP COLUMN email;
@@ -0,0 +1,8 @@
+// Package schema embeds the goose migrations that define the SQLite
+// database schema. They mirror sql/schema version for version.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
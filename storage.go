@@ -0,0 +1,52 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gator/internal/database"
+	"gator/internal/database/sqlite"
+	"gator/internal/migrate"
+	"gator/sql/schema"
+	sqliteschema "gator/sql/sqlite/schema"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// openStorage connects to the database named by dbURL. URLs starting with
+// sqlite: use a local SQLite file, anything else is passed to PostgreSQL.
+func openStorage(dbURL string) (*sql.DB, database.Store, *migrate.Migrator, error) {
+	if path, ok := sqlitePath(dbURL); ok {
+		db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
+		}
+		migrator, err := migrate.New(db, migrate.SQLite, sqliteschema.FS)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+		}
+		return db, sqlite.NewStore(db), migrator, nil
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	migrator, err := migrate.New(db, migrate.Postgres, schema.FS)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return db, database.NewStore(db), migrator, nil
+}
+
+// sqlitePath extracts the file path from sqlite:path or sqlite://path.
+func sqlitePath(dbURL string) (string, bool) {
+	for _, prefix := range []string{"sqlite://", "sqlite3://", "sqlite:", "sqlite3:"} {
+		if strings.HasPrefix(dbURL, prefix) {
+			return strings.TrimPrefix(dbURL, prefix), true
+		}
+	}
+	return "", false
+}